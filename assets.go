@@ -0,0 +1,151 @@
+package openapidocs
+
+import (
+	"embed"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//go:generate go run ./internal/assetsgen
+
+// embeddedAssets contains pinned builds of the JavaScript and CSS files of the documentation generators.
+// Each generator has its own directory. The files are downloaded by `go generate`.
+//
+//go:embed assets
+var embeddedAssets embed.FS
+
+// embeddedAssetsPath is the path under the base path of a documentation site where the embedded assets are served.
+const embeddedAssetsPath = "_assets"
+
+// embeddedAssetsCacheControl is the Cache-Control header value for the embedded assets.
+const embeddedAssetsCacheControl = "public, max-age=86400"
+
+// assetBundle describes the JavaScript and CSS files of a documentation generator.
+type assetBundle struct {
+	// dir is the directory of the embedded files.
+	dir string
+	// cdnUrl is the format of the URL of a file on the CDN. It takes the version and the file name.
+	cdnUrl string
+	// version is the known-good version of the generator. The embedded files are built from this version.
+	// Keep it in sync with internal/assetsgen.
+	version string
+}
+
+var (
+	elementsAssets = assetBundle{
+		dir:     "elements",
+		cdnUrl:  "https://unpkg.com/@stoplight/elements@%s/%s",
+		version: "8.1.0",
+	}
+	scalarAssets = assetBundle{
+		dir:     "scalar",
+		cdnUrl:  "https://cdn.jsdelivr.net/npm/@scalar/api-reference@%s/dist/browser/%s",
+		version: "1.25.0",
+	}
+	swaggerUIAssets = assetBundle{
+		dir:     "swagger-ui",
+		cdnUrl:  "https://unpkg.com/swagger-ui-dist@%s/%s",
+		version: "5.17.14",
+	}
+	redocAssets = assetBundle{
+		dir:     "redoc",
+		cdnUrl:  "https://cdn.jsdelivr.net/npm/redoc@%s/bundles/%s",
+		version: "2.1.5",
+	}
+	rapiDocAssets = assetBundle{
+		dir:     "rapidoc",
+		cdnUrl:  "https://unpkg.com/rapidoc@%s/dist/%s",
+		version: "9.3.8",
+	}
+	openAPIExplorerAssets = assetBundle{
+		dir:     "openapi-explorer",
+		cdnUrl:  "https://unpkg.com/openapi-explorer@%s/dist/browser/%s",
+		version: "2.2.733",
+	}
+	asyncAPIAssets = assetBundle{
+		dir:     "asyncapi",
+		cdnUrl:  "https://unpkg.com/@asyncapi/web-component@%s/lib/%s",
+		version: "1.4.10",
+	}
+	// asyncAPIStylesheetAssets is the stylesheet of the AsyncAPI web component, which is published in another package.
+	// It is embedded in the same directory as asyncAPIAssets.
+	asyncAPIStylesheetAssets = assetBundle{
+		dir:     "asyncapi",
+		cdnUrl:  "https://unpkg.com/@asyncapi/react-component@%s/styles/%s",
+		version: "1.4.10",
+	}
+)
+
+// embeddedFS returns the embedded files of the bundle.
+// It returns an error if the version is not the version of the embedded files or any of the files is not bundled into the package.
+func (b assetBundle) embeddedFS(version string, files ...string) (fs.FS, error) {
+	if version != b.version {
+		return nil, fmt.Errorf("the embedded %s assets are version %s, but version %s is requested", b.dir, b.version, version)
+	}
+	sub, err := fs.Sub(embeddedAssets, path.Join("assets", b.dir))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range files {
+		if _, err := fs.Stat(sub, name); err != nil {
+			return nil, fmt.Errorf("embedded asset %s/%s is not bundled into this build of the package: %w", b.dir, name, err)
+		}
+	}
+	return sub, nil
+}
+
+// url returns the URL of the file name.
+// If assets is not nil, the URL points to the embedded file under the base path.
+func (b assetBundle) url(assets fs.FS, basePath, version, name string) string {
+	if assets != nil {
+		return path.Join(basePath, embeddedAssetsPath, name)
+	}
+	return fmt.Sprintf(b.cdnUrl, version, name)
+}
+
+// integrity returns the Subresource Integrity hash of the file name of the version from assetIntegrities.
+// It returns an empty string if the hash is not known.
+func (b assetBundle) integrity(version, name string) string {
+	return assetIntegrities[path.Join(b.dir, version, name)]
+}
+
+// integrityOf returns hash if it is not empty, or the known hash of the file name of the version.
+// For a version other than the pinned one, it returns an error if neither is available, so that a file of
+// an unknown build is never loaded from the CDN without Subresource Integrity. field is the configuration field
+// of the hash, which is reported in the error.
+func (b assetBundle) integrityOf(version, name, hash, field string) (string, error) {
+	if hash != "" {
+		return hash, nil
+	}
+	if known := b.integrity(version, name); known != "" {
+		return known, nil
+	}
+	if version != b.version {
+		return "", fmt.Errorf("the Subresource Integrity hash of %s %s is not known, set %s", path.Join(b.dir, name), version, field)
+	}
+	return "", nil
+}
+
+// embeddedAssetName returns the name of the embedded asset if relPath points to the embedded assets path.
+func embeddedAssetName(relPath string) (string, bool) {
+	return strings.CutPrefix(strings.TrimPrefix(relPath, "/"), embeddedAssetsPath+"/")
+}
+
+// serveEmbeddedAsset serves the file name from the embedded assets.
+func serveEmbeddedAsset(c echo.Context, assets fs.FS, name string) error {
+	b, err := fs.ReadFile(assets, name)
+	if err != nil {
+		return echo.ErrNotFound
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, embeddedAssetsCacheControl)
+	return c.Blob(http.StatusOK, contentType, b)
+}
@@ -0,0 +1,105 @@
+package openapidocs
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"io/fs"
+	"path"
+	"testing"
+)
+
+// pinnedAssets is the files of the pinned builds of the documentation generators, which internal/assetsgen downloads.
+var pinnedAssets = []struct {
+	bundle assetBundle
+	files  []string
+}{
+	{bundle: elementsAssets, files: []string{elementsScriptFile, elementsStylesheetFile}},
+	{bundle: scalarAssets, files: []string{scalarScriptFile}},
+	{bundle: swaggerUIAssets, files: []string{swaggerUIScriptFile, swaggerUIStylesheetFile, swaggerUIStandalonePresetFile}},
+	{bundle: redocAssets, files: []string{redocScriptFile}},
+	{bundle: rapiDocAssets, files: []string{rapiDocScriptFile}},
+	{bundle: openAPIExplorerAssets, files: []string{openAPIExplorerScriptFile}},
+	{bundle: asyncAPIAssets, files: []string{asyncAPIScriptFile}},
+	{bundle: asyncAPIStylesheetAssets, files: []string{asyncAPIStylesheetFile}},
+}
+
+func TestPinnedAssetsAreEmbedded(t *testing.T) {
+	for _, pinned := range pinnedAssets {
+		for _, name := range pinned.files {
+			t.Run(path.Join(pinned.bundle.dir, name), func(t *testing.T) {
+				if _, err := pinned.bundle.embeddedFS(pinned.bundle.version, name); err != nil {
+					t.Errorf("%v; run `make go-generate` and commit the assets", err)
+				}
+			})
+		}
+	}
+}
+
+func TestPinnedAssetsHaveIntegrity(t *testing.T) {
+	for _, pinned := range pinnedAssets {
+		for _, name := range pinned.files {
+			t.Run(path.Join(pinned.bundle.dir, name), func(t *testing.T) {
+				got := pinned.bundle.integrity(pinned.bundle.version, name)
+				if got == "" {
+					t.Fatalf("the hash of %s is not in assetIntegrities; run `make go-generate` and commit the changes", path.Join(pinned.bundle.dir, pinned.bundle.version, name))
+				}
+				b, err := fs.ReadFile(embeddedAssets, path.Join("assets", pinned.bundle.dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				sum := sha512.Sum384(b)
+				if want := "sha384-" + base64.StdEncoding.EncodeToString(sum[:]); got != want {
+					t.Errorf("hash = %s, want %s of the embedded file", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestAssetBundleIntegrityOf(t *testing.T) {
+	bundle := assetBundle{dir: "test", version: "1.0.0"}
+	assetIntegrities["test/1.1.0/known.js"] = "sha384-known"
+	t.Cleanup(func() {
+		delete(assetIntegrities, "test/1.1.0/known.js")
+	})
+
+	tests := []struct {
+		name    string
+		version string
+		file    string
+		hash    string
+		want    string
+		wantErr bool
+	}{
+		{name: "given hash", version: "2.0.0", file: "unknown.js", hash: "sha384-given", want: "sha384-given"},
+		{name: "known hash", version: "1.1.0", file: "known.js", want: "sha384-known"},
+		{name: "pinned version without a known hash", version: "1.0.0", file: "unknown.js", want: ""},
+		{name: "other version without a hash", version: "2.0.0", file: "unknown.js", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bundle.integrityOf(tt.version, tt.file, tt.hash, "ScriptIntegrity")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("integrityOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRendererRejectsAssetVersionWithoutIntegrity(t *testing.T) {
+	if _, err := NewSwaggerUIRenderer(SwaggerUIConfig{AssetVersion: "0.0.1"}); err == nil {
+		t.Error("err = nil, want an error for the missing hashes")
+	}
+	_, err := NewSwaggerUIRenderer(SwaggerUIConfig{
+		AssetVersion:              "0.0.1",
+		ScriptIntegrity:           "sha384-script",
+		StylesheetIntegrity:       "sha384-stylesheet",
+		StandalonePresetIntegrity: "sha384-preset",
+	})
+	if err != nil {
+		t.Errorf("err = %v, want nil with all the hashes set", err)
+	}
+}
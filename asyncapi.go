@@ -0,0 +1,246 @@
+package openapidocs
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+)
+
+// AsyncAPIConfig is the configuration for AsyncAPIDocumentsHandler to generate the AsyncAPI documentation with the AsyncAPI web component.
+// Some fields are AsyncAPI component configuration options.
+// See https://github.com/asyncapi/asyncapi-react/blob/master/docs/configuration/config-modification.md
+type AsyncAPIConfig struct {
+	// Spec is the AsyncAPI specification.
+	Spec string
+	// SpecUrl is the URL of the AsyncAPI specification. If Spec is not empty, SpecUrl is ignored.
+	SpecUrl string
+	// SiteConfig is the configuration of the documentation site that is common to all renderers,
+	// such as the source of the specification and HTTP caching.
+	SiteConfig
+	// Title is the title of the page.
+	Title string
+	// Template is a template string for rendering the page with html/template.
+	Template string
+	// UseEmbeddedAssets makes the page load the AsyncAPI web component JavaScript and CSS files embedded in this package
+	// instead of loading them from a CDN. The files are served under the `_assets` path of the documentation site.
+	UseEmbeddedAssets bool
+	// AssetVersion is the version of the AsyncAPI web component to load. The default is the version of the embedded assets.
+	AssetVersion string
+	// ScriptIntegrity is the Subresource Integrity hash of the AsyncAPI web component JavaScript file.
+	// If it is empty, the known hash of AssetVersion is used.
+	ScriptIntegrity string
+
+	// ShowSidebar is the AsyncAPI component `show.sidebar` configuration.
+	ShowSidebar bool
+	// HideInfo is the negation of the AsyncAPI component `show.info` configuration.
+	HideInfo bool
+	// HideServers is the negation of the AsyncAPI component `show.servers` configuration.
+	HideServers bool
+	// HideOperations is the negation of the AsyncAPI component `show.operations` configuration.
+	HideOperations bool
+	// HideMessages is the negation of the AsyncAPI component `show.messages` configuration.
+	HideMessages bool
+	// HideSchemas is the negation of the AsyncAPI component `show.schemas` configuration.
+	HideSchemas bool
+	// HideErrors is the negation of the AsyncAPI component `show.errors` configuration.
+	HideErrors bool
+	// ExpandMessageExamples is the AsyncAPI component `expand.messageExamples` configuration.
+	ExpandMessageExamples bool
+}
+
+type asyncAPITemplateParams struct {
+	AsyncAPIConfig
+	BasePath               string
+	Specs                  []PageSpec
+	LiveReloadUrl          string
+	SpecUrl                string
+	ComponentConfiguration string
+	ScriptUrl              string
+	StylesheetUrl          string
+}
+
+type asyncAPIComponentConfiguration struct {
+	Show   asyncAPIComponentConfigurationShow   `json:"show"`
+	Expand asyncAPIComponentConfigurationExpand `json:"expand"`
+}
+
+type asyncAPIComponentConfigurationShow struct {
+	Sidebar    bool `json:"sidebar"`
+	Info       bool `json:"info"`
+	Servers    bool `json:"servers"`
+	Operations bool `json:"operations"`
+	Messages   bool `json:"messages"`
+	Schemas    bool `json:"schemas"`
+	Errors     bool `json:"errors"`
+}
+
+type asyncAPIComponentConfigurationExpand struct {
+	MessageExamples bool `json:"messageExamples"`
+}
+
+var DefaultAsyncAPIConfig = AsyncAPIConfig{
+	Spec:                  "",
+	SpecUrl:               "",
+	SiteConfig:            DefaultSiteConfig,
+	Title:                 "API documentation with AsyncAPI",
+	Template:              defaultAsyncAPITemplate,
+	UseEmbeddedAssets:     false,
+	AssetVersion:          asyncAPIAssets.version,
+	ScriptIntegrity:       "",
+	ShowSidebar:           false,
+	HideInfo:              false,
+	HideServers:           false,
+	HideOperations:        false,
+	HideMessages:          false,
+	HideSchemas:           false,
+	HideErrors:            false,
+	ExpandMessageExamples: false,
+}
+
+const (
+	asyncAPIScriptFile     = "asyncapi-web-component.js"
+	asyncAPIStylesheetFile = "default.min.css"
+)
+
+// The stylesheet is imported by the web component itself, so it can't have an integrity attribute.
+const defaultAsyncAPITemplate = `<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Title }}</title>
+  <script src="{{ .ScriptUrl }}"{{ if .ScriptIntegrity }} integrity="{{ .ScriptIntegrity }}"{{ end }} crossorigin="anonymous" defer></script>
+</head>
+<body>
+  {{- template "spec-selector" .Specs }}
+  <asyncapi-component
+    schemaUrl="{{ .SpecUrl }}"
+    config="{{ .ComponentConfiguration }}"
+    cssImportPath="{{ .StylesheetUrl }}"
+  ></asyncapi-component>
+  {{- template "live-reload" .LiveReloadUrl }}
+</body>
+</html>
+`
+
+// asyncAPIRenderer renders the page of the AsyncAPI web component.
+type asyncAPIRenderer struct {
+	config AsyncAPIConfig
+	tmpl   *htmltemplate.Template
+	assets fs.FS
+}
+
+// newAsyncAPIRenderer returns a Renderer of the AsyncAPI documentation with the AsyncAPI web component.
+// It is not exported because Documents serves and validates the specification as an OpenAPI document.
+func newAsyncAPIRenderer(config AsyncAPIConfig) (Renderer, error) {
+	if config.Template == "" {
+		config.Template = DefaultAsyncAPIConfig.Template
+	}
+	if config.Title == "" {
+		config.Title = DefaultAsyncAPIConfig.Title
+	}
+	if config.AssetVersion == "" {
+		config.AssetVersion = DefaultAsyncAPIConfig.AssetVersion
+	}
+	var err error
+	if config.ScriptIntegrity, err = asyncAPIAssets.integrityOf(config.AssetVersion, asyncAPIScriptFile, config.ScriptIntegrity, "ScriptIntegrity"); err != nil {
+		return nil, err
+	}
+
+	var assets fs.FS
+	if config.UseEmbeddedAssets {
+		if assets, err = asyncAPIAssets.embeddedFS(config.AssetVersion, asyncAPIScriptFile, asyncAPIStylesheetFile); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpl, err := parsePageTemplate(config.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	return &asyncAPIRenderer{
+		config: config,
+		tmpl:   tmpl,
+		assets: assets,
+	}, nil
+}
+
+func (r *asyncAPIRenderer) Render(w io.Writer, page Page) error {
+	componentConfiguration := asyncAPIComponentConfiguration{
+		Show: asyncAPIComponentConfigurationShow{
+			Sidebar:    r.config.ShowSidebar,
+			Info:       !r.config.HideInfo,
+			Servers:    !r.config.HideServers,
+			Operations: !r.config.HideOperations,
+			Messages:   !r.config.HideMessages,
+			Schemas:    !r.config.HideSchemas,
+			Errors:     !r.config.HideErrors,
+		},
+		Expand: asyncAPIComponentConfigurationExpand{
+			MessageExamples: r.config.ExpandMessageExamples,
+		},
+	}
+
+	jsonData, err := json.Marshal(componentConfiguration)
+	if err != nil {
+		return err
+	}
+
+	return r.tmpl.Execute(w, asyncAPITemplateParams{
+		AsyncAPIConfig:         r.config,
+		BasePath:               page.BasePath,
+		Specs:                  page.Specs,
+		LiveReloadUrl:          page.LiveReloadUrl,
+		SpecUrl:                page.SpecUrl,
+		ComponentConfiguration: string(jsonData),
+		ScriptUrl:              asyncAPIAssets.url(r.assets, page.BasePath, r.config.AssetVersion, asyncAPIScriptFile),
+		StylesheetUrl:          asyncAPIStylesheetAssets.url(r.assets, page.BasePath, r.config.AssetVersion, asyncAPIStylesheetFile),
+	})
+}
+
+func (r *asyncAPIRenderer) Assets() fs.FS {
+	return r.assets
+}
+
+// NewAsyncAPIHandler returns an echo.HandlerFunc to serve the AsyncAPI documentation with the AsyncAPI web component.
+// The specification is served under the `asyncapi-spec` path of the documentation site.
+// It returns an error if the configuration is invalid.
+func NewAsyncAPIHandler(config AsyncAPIConfig) (echo.HandlerFunc, error) {
+	renderer, err := newAsyncAPIRenderer(config)
+	if err != nil {
+		return nil, err
+	}
+	h, _, err := newDocumentsHandler(DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer, asyncAPISpecKind)
+	return h, err
+}
+
+// AsyncAPIDocumentsHandler returns an echo.HandlerFunc to serve the AsyncAPI documentation with the AsyncAPI web component.
+// It panics if the configuration is invalid. Use NewAsyncAPIHandler to handle the error.
+func AsyncAPIDocumentsHandler(config AsyncAPIConfig) echo.HandlerFunc {
+	h, err := NewAsyncAPIHandler(config)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// AsyncAPIDocuments registers a handler to serve the AsyncAPI documentation with the AsyncAPI web component.
+// It panics if the configuration is invalid. Use RegisterAsyncAPIDocuments to handle the error.
+func AsyncAPIDocuments(e *echo.Echo, pathPrefix string, config AsyncAPIConfig) {
+	if err := RegisterAsyncAPIDocuments(e, pathPrefix, config); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterAsyncAPIDocuments registers a handler to serve the AsyncAPI documentation with the AsyncAPI web component on r,
+// which is either *echo.Echo or *echo.Group. The middleware is applied to the handler in addition to the middleware of r.
+// It returns an error if the configuration is invalid.
+func RegisterAsyncAPIDocuments(r Router, pathPrefix string, config AsyncAPIConfig, middleware ...echo.MiddlewareFunc) error {
+	renderer, err := newAsyncAPIRenderer(config)
+	if err != nil {
+		return err
+	}
+	return registerDocuments(r, pathPrefix, DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer, asyncAPISpecKind, "AsyncAPI", config.Title, middleware)
+}
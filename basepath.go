@@ -0,0 +1,65 @@
+package openapidocs
+
+import (
+	"github.com/labstack/echo/v4"
+	"path"
+	"strings"
+)
+
+const (
+	// headerXForwardedPrefix is the header set by reverse proxies that strip a path prefix from the request.
+	// Its value is the stripped prefix.
+	headerXForwardedPrefix = "X-Forwarded-Prefix"
+	// headerXForwardedPath is the header set by reverse proxies that rewrite the request path.
+	// Its value is the original path requested by the client.
+	headerXForwardedPath = "X-Forwarded-Path"
+)
+
+// publicBasePath returns the base path of the documentation site as seen by clients.
+// basePath is the base path on this server, and relPath is the requested path under the base path.
+//
+// The configured path takes precedence. Otherwise, if trusted is true, the base path is derived from
+// the X-Forwarded-Prefix or X-Forwarded-Path header, so that the generated URLs work behind path-rewriting
+// reverse proxies. The headers are ignored by default, because any client can send them.
+// The response varies by the headers if they are trusted.
+func publicBasePath(c echo.Context, configured string, trusted bool, basePath, relPath string) string {
+	if configured != "" {
+		return configured
+	}
+	if !trusted {
+		return basePath
+	}
+
+	r := c.Request()
+	c.Response().Header().Add(echo.HeaderVary, headerXForwardedPrefix+", "+headerXForwardedPath)
+
+	if v := r.Header.Get(headerXForwardedPrefix); v != "" {
+		if prefix, ok := cleanForwardedPath(v); ok {
+			return strings.TrimSuffix(prefix, "/") + basePath
+		}
+	}
+
+	if v := r.Header.Get(headerXForwardedPath); v != "" {
+		if p, ok := cleanForwardedPath(v); ok && strings.HasSuffix(p, relPath) {
+			return strings.TrimSuffix(p, relPath)
+		}
+	}
+
+	return basePath
+}
+
+// cleanForwardedPath sanitizes a path in a forwarded header.
+// Only the first value is used if the header has multiple values.
+// It reports false if the value is not an absolute path, so that a header can't redirect clients to another host.
+func cleanForwardedPath(v string) (string, bool) {
+	v, _, _ = strings.Cut(v, ",")
+	v, _, _ = strings.Cut(strings.TrimSpace(v), "?")
+	if !strings.HasPrefix(v, "/") || strings.HasPrefix(v, "//") || strings.ContainsAny(v, "\\\"'<> ") {
+		return "", false
+	}
+	cleaned := path.Clean(v)
+	if strings.HasSuffix(v, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned, true
+}
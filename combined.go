@@ -0,0 +1,237 @@
+package openapidocs
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// CombinedConfig is the configuration for CombinedDocumentsHandler to generate the OpenAPI documentation with
+// Stoplight Elements, Scalar, Swagger UI and Redoc from a single specification.
+// Each documentation generator has its page at `<prefix>/elements`, `<prefix>/scalar`, `<prefix>/swagger-ui` and
+// `<prefix>/redoc`, and each page has a switcher to the other pages.
+// The specification is loaded once and served at `<prefix>/openapi-spec` for all the pages.
+type CombinedConfig struct {
+	// Spec is the OpenAPI specification.
+	Spec string
+	// SpecUrl is the URL of the OpenAPI specification. If Spec is not empty, SpecUrl is ignored.
+	SpecUrl string
+	// SiteConfig is the configuration of the documentation site that is common to all renderers,
+	// such as the source of the specification and HTTP caching. It is shared by all the pages.
+	SiteConfig
+	// Title is the title of the pages. It is used for the pages whose configuration doesn't have a title.
+	Title string
+	// DefaultRenderer is the page that the base path of the documentation site redirects to.
+	DefaultRenderer CombinedRenderer
+
+	// Elements is the configuration of the Stoplight Elements page.
+	// The specification and the site configuration in it, such as Spec and SiteConfig, are ignored.
+	Elements ElementsConfig
+	// Scalar is the configuration of the Scalar page.
+	// The specification and the site configuration in it, such as Spec and SiteConfig, are ignored.
+	Scalar ScalarConfig
+	// SwaggerUI is the configuration of the Swagger UI page.
+	// The specification and the site configuration in it, such as Spec and SiteConfig, are ignored.
+	SwaggerUI SwaggerUIConfig
+	// Redoc is the configuration of the Redoc page.
+	// The specification and the site configuration in it, such as Spec and SiteConfig, are ignored.
+	Redoc RedocConfig
+}
+
+// CombinedRenderer is a page of a combined documentation site. It is the path of the page under the base path.
+type CombinedRenderer string
+
+const (
+	CombinedRendererElements  CombinedRenderer = "elements"
+	CombinedRendererScalar    CombinedRenderer = "scalar"
+	CombinedRendererSwaggerUI CombinedRenderer = "swagger-ui"
+	CombinedRendererRedoc     CombinedRenderer = "redoc"
+)
+
+var DefaultCombinedConfig = CombinedConfig{
+	Spec:            "",
+	SpecUrl:         "",
+	SiteConfig:      DefaultSiteConfig,
+	Title:           "",
+	DefaultRenderer: CombinedRendererElements,
+	Elements:        ElementsConfig{},
+	Scalar:          ScalarConfig{},
+	SwaggerUI:       SwaggerUIConfig{},
+	Redoc:           RedocConfig{},
+}
+
+// PageRenderer is a page of a combined documentation site listed on its pages.
+type PageRenderer struct {
+	// Name is the path of the page under the base path of the site, such as `elements`.
+	Name string
+	// Title is the name of the documentation generator of the page.
+	Title string
+	// Url is the URL of the page. It keeps the selected specification of a site that hosts several specifications.
+	Url string
+	// Selected reports whether it is the current page.
+	Selected bool
+}
+
+// rendererSwitcherTemplate is the renderer switcher of a combined documentation site.
+// Page templates render it with `{{ template "renderer-switcher" .Renderers }}`. It renders nothing for other sites.
+const rendererSwitcherTemplate = `
+{{- if . }}
+<nav class="renderer-switcher" aria-label="Documentation viewers" style="margin: 0; padding: 0.5rem 1rem; border-bottom: 1px solid #d0d7de; font-family: system-ui, sans-serif; font-size: 14px;">
+  {{- range . }}
+  <a href="{{ .Url }}"{{ if .Selected }} aria-current="page"{{ end }} style="margin-right: 1rem;{{ if .Selected }} font-weight: bold;{{ end }}">{{ .Title }}</a>
+  {{- end }}
+</nav>
+{{- end }}`
+
+// combinedPage is a page of a combined documentation site.
+type combinedPage struct {
+	name  CombinedRenderer
+	title string
+	site  *documentsSite
+}
+
+// NewCombinedHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with
+// Stoplight Elements, Scalar, Swagger UI and Redoc.
+// It returns an error if the configuration is invalid.
+func NewCombinedHandler(config CombinedConfig) (echo.HandlerFunc, error) {
+	h, _, err := newCombinedHandler(config)
+	return h, err
+}
+
+// newCombinedHandler returns an echo.HandlerFunc to serve the combined documentation, and the specifications served by it.
+func newCombinedHandler(config CombinedConfig) (echo.HandlerFunc, []*servedSpec, error) {
+	if config.CacheControl == "" {
+		config.CacheControl = DefaultCombinedConfig.CacheControl
+	}
+	if config.DefaultRenderer == "" {
+		config.DefaultRenderer = DefaultCombinedConfig.DefaultRenderer
+	}
+	if config.Elements.Title == "" {
+		config.Elements.Title = config.Title
+	}
+	if config.Scalar.Title == "" {
+		config.Scalar.Title = config.Title
+	}
+	if config.SwaggerUI.Title == "" {
+		config.SwaggerUI.Title = config.Title
+	}
+	if config.Redoc.Title == "" {
+		config.Redoc.Title = config.Title
+	}
+
+	docsConfig := DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}
+	specs, err := newServedSpecs(docsConfig, openAPISpecKind)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pages := []*combinedPage{
+		{name: CombinedRendererElements, title: "Stoplight Elements"},
+		{name: CombinedRendererScalar, title: "Scalar"},
+		{name: CombinedRendererSwaggerUI, title: "Swagger UI"},
+		{name: CombinedRendererRedoc, title: "Redoc"},
+	}
+	defaultPageFound := false
+	for _, page := range pages {
+		if page.name == config.DefaultRenderer {
+			defaultPageFound = true
+		}
+	}
+	if !defaultPageFound {
+		return nil, nil, fmt.Errorf("unknown default renderer %q", config.DefaultRenderer)
+	}
+
+	for _, page := range pages {
+		var renderer Renderer
+		var err error
+		switch page.name {
+		case CombinedRendererElements:
+			renderer, err = NewElementsRenderer(config.Elements)
+		case CombinedRendererScalar:
+			renderer, err = NewScalarRenderer(config.Scalar)
+		case CombinedRendererSwaggerUI:
+			renderer, err = NewSwaggerUIRenderer(config.SwaggerUI)
+		case CombinedRendererRedoc:
+			renderer, err = NewRedocRenderer(config.Redoc)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", page.title, err)
+		}
+
+		current := page
+		switcher := func(specBasePath string, selected *servedSpec) []PageRenderer {
+			list := make([]PageRenderer, 0, len(pages))
+			for _, p := range pages {
+				u := path.Join(specBasePath, string(p.name))
+				if selected.name != "" {
+					u += "?" + specQueryParam + "=" + url.QueryEscape(selected.name)
+				}
+				list = append(list, PageRenderer{
+					Name:     string(p.name),
+					Title:    p.title,
+					Url:      u,
+					Selected: p == current,
+				})
+			}
+			return list
+		}
+		if page.site, err = newDocumentsSite(docsConfig, renderer, specs, switcher); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", page.title, err)
+		}
+	}
+
+	return func(c echo.Context) error {
+		relPath := c.Param("*")
+		if handled, err := serveSiteEndpoints(c, config.SiteConfig, specs, relPath); handled {
+			return err
+		}
+		basePath := siteBasePath(c, config.SiteConfig, relPath)
+
+		name, rest, found := strings.Cut(strings.TrimPrefix(relPath, "/"), "/")
+		if found {
+			rest = "/" + rest
+		}
+		for _, page := range pages {
+			if string(page.name) == name {
+				return page.site.servePage(c, path.Join(basePath, name), basePath, rest)
+			}
+		}
+		return redirectKeepingQuery(c, path.Join(basePath, string(config.DefaultRenderer)))
+	}, specs, nil
+}
+
+// CombinedDocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with
+// Stoplight Elements, Scalar, Swagger UI and Redoc.
+// It panics if the configuration is invalid. Use NewCombinedHandler to handle the error.
+func CombinedDocumentsHandler(config CombinedConfig) echo.HandlerFunc {
+	h, err := NewCombinedHandler(config)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// CombinedDocuments registers a handler to serve the OpenAPI documentation with
+// Stoplight Elements, Scalar, Swagger UI and Redoc.
+// It panics if the configuration is invalid. Use RegisterCombinedDocuments to handle the error.
+func CombinedDocuments(e *echo.Echo, pathPrefix string, config CombinedConfig) {
+	if err := RegisterCombinedDocuments(e, pathPrefix, config); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterCombinedDocuments registers a handler to serve the OpenAPI documentation with
+// Stoplight Elements, Scalar, Swagger UI and Redoc on r, which is either *echo.Echo or *echo.Group.
+// The middleware is applied to the handler in addition to the middleware of r.
+// It returns an error if the configuration is invalid.
+func RegisterCombinedDocuments(r Router, pathPrefix string, config CombinedConfig, middleware ...echo.MiddlewareFunc) error {
+	h, specs, err := newCombinedHandler(config)
+	if err != nil {
+		return err
+	}
+	route := r.GET(pathPrefix+"*", h, middleware...)
+	registerDocsSite(route, newDocsSite(route.Path, "Stoplight Elements, Scalar, Swagger UI, Redoc", config.Title, openAPISpecKind, config.PublicBasePath, specs))
+	return nil
+}
@@ -0,0 +1,104 @@
+package openapidocs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/andybalholm/brotli"
+	"strconv"
+	"strings"
+)
+
+// compressMinSize is the minimum size of a content to be precompressed.
+// Smaller contents are not worth the overhead of compression.
+const compressMinSize = 1024
+
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// encodedContent is a compressed variant of a content.
+type encodedContent struct {
+	encoding string
+	body     []byte
+	etag     string
+}
+
+// precompress compresses data with brotli and gzip, in the order of preference.
+// etag is the ETag of the uncompressed data. Each variant has its own ETag derived from it.
+// Variants that are not smaller than data are omitted.
+func precompress(data []byte, etag string) []encodedContent {
+	if len(data) < compressMinSize {
+		return nil
+	}
+
+	var variants []encodedContent
+	for _, encoding := range []string{encodingBrotli, encodingGzip} {
+		body, err := compress(encoding, data)
+		if err != nil || len(body) >= len(data) {
+			continue
+		}
+		variants = append(variants, encodedContent{
+			encoding: encoding,
+			body:     body,
+			etag:     strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`,
+		})
+	}
+	return variants
+}
+
+func compress(encoding string, data []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	var err error
+	switch encoding {
+	case encodingBrotli:
+		w := brotli.NewWriterLevel(buf, brotli.DefaultCompression)
+		if _, err = w.Write(data); err == nil {
+			err = w.Close()
+		}
+	case encodingGzip:
+		w, _ := gzip.NewWriterLevel(buf, gzip.BestCompression)
+		if _, err = w.Write(data); err == nil {
+			err = w.Close()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// negotiateEncoding returns the variant with the highest quality in the Accept-Encoding header.
+// When the qualities are equal, the earlier variant wins. It returns nil if the uncompressed content should be sent.
+func negotiateEncoding(acceptEncoding string, variants []encodedContent) *encodedContent {
+	if acceptEncoding == "" || len(variants) == 0 {
+		return nil
+	}
+
+	qualities := map[string]float64{}
+	for _, r := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(r), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		qualities[strings.ToLower(strings.TrimSpace(coding))] = q
+	}
+
+	var best *encodedContent
+	bestQ := 0.0
+	for i := range variants {
+		q, ok := qualities[variants[i].encoding]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > bestQ {
+			best = &variants[i]
+			bestQ = q
+		}
+	}
+	return best
+}
@@ -0,0 +1,314 @@
+package openapidocs
+
+import (
+	"bytes"
+	"github.com/labstack/echo/v4"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DocsIndexConfig is the configuration for DocsIndex to generate the index page of the documentation sites.
+type DocsIndexConfig struct {
+	// Title is the title of the page.
+	Title string
+	// Template is a template string for rendering the page with html/template.
+	Template string
+	// CacheControl is the Cache-Control header value for the page.
+	// The responses always have an ETag, so the default `no-cache` lets clients revalidate them cheaply.
+	CacheControl string
+	// TrustForwardedHeaders makes the links of the page start with the X-Forwarded-Prefix header.
+	// Enable it only behind a reverse proxy that sets or removes the header, because any client can send it.
+	TrustForwardedHeaders bool
+}
+
+type docsIndexTemplateParams struct {
+	DocsIndexConfig
+	Query   string
+	Entries []docsIndexEntry
+}
+
+// docsIndexEntry is a specification listed on the index page.
+type docsIndexEntry struct {
+	Title     string
+	Generator string
+	Version   string
+	DocsUrl   string
+	SpecUrl   string
+	// SearchText is the lower-cased text the search matches against.
+	SearchText string
+}
+
+var DefaultDocsIndexConfig = DocsIndexConfig{
+	Title:                 "API documentation",
+	Template:              defaultDocsIndexTemplate,
+	CacheControl:          defaultCacheControl,
+	TrustForwardedHeaders: false,
+}
+
+// docsIndexQueryParam is the query parameter of the search on the index page.
+const docsIndexQueryParam = "q"
+
+const defaultDocsIndexTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Title }}</title>
+  <style>
+    body { margin: 0; font-family: system-ui, -apple-system, "Segoe UI", sans-serif; line-height: 1.5; color: #1f2328; }
+    main { max-width: 64rem; margin: 0 auto; padding: 0 1rem 2rem; }
+    a { color: #0550ae; }
+    form { display: flex; gap: 0.5rem; align-items: center; margin: 1rem 0; }
+    input[type=search] { flex: 1; padding: 0.375rem 0.5rem; font: inherit; }
+    table { border-collapse: collapse; width: 100%; }
+    caption { text-align: left; color: #59636e; }
+    th, td { border-bottom: 1px solid #d0d7de; padding: 0.375rem 0.5rem; text-align: left; vertical-align: top; }
+  </style>
+</head>
+<body>
+  <main>
+    <h1>{{ .Title }}</h1>
+    <form method="get" role="search">
+      <label for="docs-index-search">Search</label>
+      <input id="docs-index-search" type="search" name="q" value="{{ .Query }}" placeholder="Title, generator or version" autocomplete="off">
+      <button type="submit">Search</button>
+    </form>
+    {{- if .Entries }}
+    <table id="docs-index">
+      <caption>Registered documentation</caption>
+      <thead>
+        <tr><th scope="col">Title</th><th scope="col">Generator</th><th scope="col">Version</th><th scope="col">Links</th></tr>
+      </thead>
+      <tbody>
+        {{- range .Entries }}
+        <tr data-search="{{ .SearchText }}">
+          <th scope="row"><a href="{{ .DocsUrl }}">{{ .Title }}</a></th>
+          <td>{{ .Generator }}</td>
+          <td>{{ with .Version }}<code>{{ . }}</code>{{ end }}</td>
+          <td><a href="{{ .DocsUrl }}">Documentation</a> · <a href="{{ .SpecUrl }}">Specification</a></td>
+        </tr>
+        {{- end }}
+      </tbody>
+    </table>
+    <p id="docs-index-empty" hidden>No documentation matches the search.</p>
+    <script>
+      (function () {
+        var input = document.getElementById("docs-index-search");
+        var rows = document.getElementById("docs-index").tBodies[0].rows;
+        var empty = document.getElementById("docs-index-empty");
+        input.addEventListener("input", function () {
+          var q = input.value.trim().toLowerCase();
+          var shown = 0;
+          for (var i = 0; i < rows.length; i++) {
+            rows[i].hidden = rows[i].dataset.search.indexOf(q) === -1;
+            if (!rows[i].hidden) {
+              shown++;
+            }
+          }
+          empty.hidden = shown !== 0;
+        });
+      })();
+    </script>
+    {{- else if .Query }}
+    <p>No documentation matches the search.</p>
+    {{- else }}
+    <p>No documentation is registered.</p>
+    {{- end }}
+  </main>
+</body>
+</html>
+`
+
+// docsSite is a documentation site registered by one of the Register*Documents functions.
+type docsSite struct {
+	// path is the path of the route of the site.
+	path string
+	// generator is the name of the documentation generator.
+	generator      string
+	title          string
+	publicBasePath string
+	kind           specKind
+	// specs is the specifications served by the site, which are shared with its handler.
+	specs []*servedSpec
+}
+
+// newDocsSite returns the docsSite of a documentation site of the route path, which serves specs.
+func newDocsSite(path string, generator, title string, kind specKind, publicBasePath string, specs []*servedSpec) *docsSite {
+	return &docsSite{
+		path:           path,
+		generator:      generator,
+		title:          title,
+		publicBasePath: publicBasePath,
+		kind:           kind,
+		specs:          specs,
+	}
+}
+
+// specInfo is the information in the `info` object of a specification.
+type specInfo struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+// servedSpecInfo returns the information of the current document of s.
+// It returns the zero value if s is given by SpecUrl or can't be parsed.
+func servedSpecInfo(s *servedSpec) specInfo {
+	var info specInfo
+	if doc := s.doc.Load(); doc != nil && doc.root != nil {
+		if node := mappingValue(doc.root, "info"); node != nil {
+			_ = node.Decode(&info)
+		}
+	}
+	return info
+}
+
+// entries returns the index entries of the specifications of the site.
+// prefix is the path prefix added by a reverse proxy in front of the index page.
+func (s *docsSite) entries(prefix string) []docsIndexEntry {
+	basePath := s.publicBasePath
+	if basePath == "" {
+		basePath = prefix + strings.TrimSuffix(s.path, "*")
+	}
+	if basePath == "" {
+		basePath = "/"
+	}
+
+	if len(s.specs) == 1 && s.specs[0].name == "" {
+		spec := s.specs[0]
+		info := servedSpecInfo(spec)
+		title := s.title
+		if title == "" {
+			title = info.Title
+		}
+		if title == "" {
+			title = basePath
+		}
+		return []docsIndexEntry{newDocsIndexEntry(title, s.generator, info.Version, basePath, spec.specUrl(basePath))}
+	}
+
+	entries := make([]docsIndexEntry, 0, len(s.specs))
+	for _, spec := range s.specs {
+		info := servedSpecInfo(spec)
+		title := spec.title
+		if title == "" {
+			title = info.Title
+		}
+		if title == "" {
+			title = spec.name
+		}
+		docsUrl := basePath + "?" + specQueryParam + "=" + url.QueryEscape(spec.name)
+		entries = append(entries, newDocsIndexEntry(title, s.generator, info.Version, docsUrl, spec.specUrl(basePath)))
+	}
+	return entries
+}
+
+func newDocsIndexEntry(title, generator, version, docsUrl, specUrl string) docsIndexEntry {
+	return docsIndexEntry{
+		Title:      title,
+		Generator:  generator,
+		Version:    version,
+		DocsUrl:    docsUrl,
+		SpecUrl:    specUrl,
+		SearchText: strings.ToLower(strings.Join([]string{title, generator, version, docsUrl}, " ")),
+	}
+}
+
+// NewDocsIndexHandler returns an echo.HandlerFunc to serve the index page of the documentation sites registered on e.
+// The page lists the sites registered by the Documents and Register*Documents functions, including the ones registered
+// after the handler is created.
+// It returns an error if the configuration is invalid.
+func NewDocsIndexHandler(e *echo.Echo, config DocsIndexConfig) (echo.HandlerFunc, error) {
+	if config.Title == "" {
+		config.Title = DefaultDocsIndexConfig.Title
+	}
+	if config.Template == "" {
+		config.Template = DefaultDocsIndexConfig.Template
+	}
+	if config.CacheControl == "" {
+		config.CacheControl = DefaultDocsIndexConfig.CacheControl
+	}
+
+	tmpl, err := parsePageTemplate(config.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	render := func(query string, entries []docsIndexEntry) ([]byte, error) {
+		buf := new(bytes.Buffer)
+		if err := tmpl.Execute(buf, docsIndexTemplateParams{
+			DocsIndexConfig: config,
+			Query:           query,
+			Entries:         entries,
+		}); err != nil {
+			return nil, &RenderError{Err: err}
+		}
+		return buf.Bytes(), nil
+	}
+
+	// Render the page once to report errors in the template when the handler is created.
+	if _, err := render("", nil); err != nil {
+		return nil, err
+	}
+
+	return func(c echo.Context) error {
+		prefix := ""
+		if config.TrustForwardedHeaders {
+			c.Response().Header().Add(echo.HeaderVary, headerXForwardedPrefix)
+			if p, ok := cleanForwardedPath(c.Request().Header.Get(headerXForwardedPrefix)); ok {
+				prefix = strings.TrimSuffix(p, "/")
+			}
+		}
+
+		query := strings.TrimSpace(c.QueryParam(docsIndexQueryParam))
+		q := strings.ToLower(query)
+		var entries []docsIndexEntry
+		for _, site := range registeredDocsSites(e) {
+			for _, entry := range site.entries(prefix) {
+				if strings.Contains(entry.SearchText, q) {
+					entries = append(entries, entry)
+				}
+			}
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return strings.ToLower(entries[i].Title) < strings.ToLower(entries[j].Title)
+		})
+
+		body, err := render(query, entries)
+		if err != nil {
+			return err
+		}
+		return writeCacheable(c, echo.MIMETextHTMLCharsetUTF8, body, computeETag(body), time.Time{}, config.CacheControl)
+	}, nil
+}
+
+// DocsIndexHandler returns an echo.HandlerFunc to serve the index page of the documentation sites registered on e.
+// It panics if the configuration is invalid. Use NewDocsIndexHandler to handle the error.
+func DocsIndexHandler(e *echo.Echo, config DocsIndexConfig) echo.HandlerFunc {
+	h, err := NewDocsIndexHandler(e, config)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// DocsIndex registers a handler on e to serve the searchable index page of the documentation sites registered on e
+// with DefaultDocsIndexConfig. Use RegisterDocsIndex to configure the page.
+func DocsIndex(e *echo.Echo, path string) {
+	if err := RegisterDocsIndex(e, path, DefaultDocsIndexConfig); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterDocsIndex registers a handler on e to serve the searchable index page of the documentation sites registered on e.
+// The middleware is applied to the handler in addition to the middleware of e.
+// It returns an error if the configuration is invalid.
+func RegisterDocsIndex(e *echo.Echo, path string, config DocsIndexConfig, middleware ...echo.MiddlewareFunc) error {
+	h, err := NewDocsIndexHandler(e, config)
+	if err != nil {
+		return err
+	}
+	registerDocsIndex(e.GET(path, h, middleware...))
+	return nil
+}
@@ -0,0 +1,328 @@
+package openapidocs
+
+import (
+	"bytes"
+	"github.com/labstack/echo/v4"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Renderer renders the page of a documentation site.
+// The built-in documentation generators implement it, and a third-party generator can implement it to be served
+// by Documents, which takes care of the specification endpoint, the base path, redirects and HTTP caching.
+type Renderer interface {
+	// Render writes the HTML of the page to w.
+	Render(w io.Writer, page Page) error
+}
+
+// Page is the information about a documentation page passed to Renderer.Render.
+type Page struct {
+	// BasePath is the base path of the documentation site as seen by clients.
+	BasePath string
+	// SpecUrl is the URL of the OpenAPI specification.
+	SpecUrl string
+	// Spec is the OpenAPI specification in JSON, for renderers that render the specification on the server.
+	// It is nil if the specification is given by SpecUrl or can't be parsed.
+	Spec []byte
+	// Specs is the list of the specifications of a documentation site that hosts several specifications,
+	// for rendering a definition selector. SpecUrl and Spec are those of the selected one.
+	// It is nil if the site hosts a single specification.
+	Specs []PageSpec
+	// Renderers is the list of the renderers of a combined documentation site, for rendering a renderer switcher.
+	// It is nil for other sites.
+	Renderers []PageRenderer
+	// LiveReloadUrl is the URL of the Server-Sent Events endpoint of the live reload, for rendering the live reload script.
+	// It is empty if the live reload is disabled.
+	LiveReloadUrl string
+}
+
+// AssetsRenderer is implemented by a Renderer that serves its own static files, such as JavaScript and CSS files.
+type AssetsRenderer interface {
+	Renderer
+	// Assets returns the files served under the `_assets` path of the documentation site, or nil if there are none.
+	Assets() fs.FS
+}
+
+// SubPathRenderer is implemented by a Renderer whose page handles the paths under the base path by itself,
+// such as a page with client-side routing.
+// Documents redirects the requests for the paths under the base path to the base path for other renderers.
+type SubPathRenderer interface {
+	Renderer
+	// ServesSubPaths reports whether the page is served for the paths under the base path.
+	ServesSubPaths() bool
+}
+
+// DocumentsConfig is the configuration of a documentation site, which is common to all renderers.
+type DocumentsConfig struct {
+	// Spec is the OpenAPI specification.
+	Spec string
+	// SpecUrl is the URL of the OpenAPI specification. If Spec is not empty, SpecUrl is ignored.
+	SpecUrl string
+	// SiteConfig is the rest of the configuration of the documentation site.
+	SiteConfig
+}
+
+var DefaultDocumentsConfig = DocumentsConfig{
+	Spec:       "",
+	SpecUrl:    "",
+	SiteConfig: DefaultSiteConfig,
+}
+
+// SiteConfig is the configuration of a documentation site other than Spec and SpecUrl, such as the source of
+// the specification and HTTP caching. It is embedded in DocumentsConfig and the configurations of all renderers.
+type SiteConfig struct {
+	// Specs is the specifications of a documentation site that hosts several specifications.
+	// If it is not empty, Spec and SpecUrl are ignored. Each specification is served at `<prefix>/openapi-spec/<name>`,
+	// or `<prefix>/asyncapi-spec/<name>` for the AsyncAPI documentation, and the `spec` query parameter of the page
+	// selects the specification to show. The first one is shown by default.
+	Specs []NamedSpec
+	// SpecSource is the source of the specification, such as a file. If it is not nil, Spec and SpecUrl are ignored.
+	// Use SpecFile or SpecFS to read the specification from a file.
+	SpecSource SpecSource
+	// SharedSpec is a specification shared with other documentation sites and the validation middlewares,
+	// so that it is loaded and reloaded once. If it is not nil, Spec, SpecUrl and SpecSource are ignored,
+	// and the specification is validated by ValidateSpec of the SharedSpec instead of ValidateSpec.
+	SharedSpec *SharedSpec
+	// WatchSpec makes the handler reload the specification when SpecSource changes. It is meant for development.
+	// The handler checks SpecSource in the background at most once a second while serving requests.
+	// If the changed specification can't be loaded, parsed or validated, the last good one is kept.
+	WatchSpec bool
+	// LiveReload makes the page reload itself in the browser when the specification changes. It is meant for development.
+	// The page listens to the Server-Sent Events endpoint at `<prefix>/_live-reload`, which reloads the specification
+	// from SpecSource like WatchSpec. It works with any SpecSource, because the changes are detected by its version.
+	LiveReload bool
+	// ServeDriftReport makes the handler serve the report of CheckDrift at `<prefix>/_drift` in JSON.
+	// The report compares all the routes of echo.Echo with the OpenAPI specifications of all its documentation sites.
+	ServeDriftReport bool
+	// ValidateSpec makes the handler validate the specification when it is created.
+	// It must be an OpenAPI 3.0, 3.1 or Swagger 2.0 document, or an AsyncAPI 2.x or 3.x document for
+	// the AsyncAPI documentation, with the required fields.
+	ValidateSpec bool
+	// PublicBasePath is the base path of the documentation site as seen by clients.
+	// Set it if a reverse proxy rewrites the request path. If it is empty, the base path is determined by
+	// the X-Forwarded-Prefix or X-Forwarded-Path header if TrustForwardedHeaders is true, or the request path.
+	PublicBasePath string
+	// TrustForwardedHeaders makes the handler determine the base path by the X-Forwarded-Prefix or X-Forwarded-Path
+	// header. Enable it only behind a reverse proxy that sets or removes the headers, because any client can send them.
+	TrustForwardedHeaders bool
+	// CacheControl is the Cache-Control header value for the page and the specification.
+	// The responses always have an ETag, so the default `no-cache` lets clients revalidate them cheaply.
+	CacheControl string
+}
+
+var DefaultSiteConfig = SiteConfig{
+	Specs:                 nil,
+	SpecSource:            nil,
+	SharedSpec:            nil,
+	WatchSpec:             false,
+	LiveReload:            false,
+	ServeDriftReport:      false,
+	ValidateSpec:          false,
+	PublicBasePath:        "",
+	TrustForwardedHeaders: false,
+	CacheControl:          defaultCacheControl,
+}
+
+// NewDocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation rendered by renderer.
+// It returns an error if the configuration is invalid or the page can't be rendered.
+func NewDocumentsHandler(config DocumentsConfig, renderer Renderer) (echo.HandlerFunc, error) {
+	h, _, err := newDocumentsHandler(config, renderer, openAPISpecKind)
+	return h, err
+}
+
+// newDocumentsHandler returns an echo.HandlerFunc to serve the documentation of a specification of the kind,
+// and the specifications served by it.
+func newDocumentsHandler(config DocumentsConfig, renderer Renderer, kind specKind) (echo.HandlerFunc, []*servedSpec, error) {
+	if config.CacheControl == "" {
+		config.CacheControl = DefaultDocumentsConfig.CacheControl
+	}
+
+	specs, err := newServedSpecs(config, kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	site, err := newDocumentsSite(config, renderer, specs, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return func(c echo.Context) error {
+		relPath := c.Param("*")
+		if handled, err := serveSiteEndpoints(c, config.SiteConfig, specs, relPath); handled {
+			return err
+		}
+		basePath := siteBasePath(c, config.SiteConfig, relPath)
+		return site.servePage(c, basePath, basePath, relPath)
+	}, specs, nil
+}
+
+// serveSiteEndpoints serves the endpoints of a documentation site other than its pages: the specifications,
+// the live reload endpoint and the drift report. It reports whether relPath is one of the endpoints.
+func serveSiteEndpoints(c echo.Context, config SiteConfig, specs []*servedSpec, relPath string) (bool, error) {
+	if s, format, ok := findSpecEndpoint(specs, relPath); ok {
+		return true, s.serve(c, format, config.CacheControl)
+	}
+	if config.LiveReload && strings.TrimPrefix(relPath, "/") == liveReloadPath {
+		return true, serveLiveReload(c, specs)
+	}
+	if config.ServeDriftReport && strings.TrimPrefix(relPath, "/") == driftReportPath {
+		return true, serveDriftReport(c)
+	}
+	return false, nil
+}
+
+// siteBasePath returns the base path of a documentation site in the URLs of its pages.
+func siteBasePath(c echo.Context, config SiteConfig, relPath string) string {
+	p := c.Request().URL.Path
+	return publicBasePath(c, config.PublicBasePath, config.TrustForwardedHeaders, strings.TrimSuffix(p, relPath), relPath)
+}
+
+// documentsSite serves the pages of a documentation site rendered by a renderer.
+// The specifications are served by the owner of the site, so that several sites can share them.
+type documentsSite struct {
+	cacheControl   string
+	renderer       Renderer
+	specs          []*servedSpec
+	assets         fs.FS
+	servesSubPaths bool
+	liveReload     bool
+	// switcher returns the renderers of a combined documentation site for the page, or nil.
+	switcher func(specBasePath string, selected *servedSpec) []PageRenderer
+	modTime  time.Time
+	pages    *pageCache
+}
+
+// newDocumentsSite returns a documentsSite that renders the pages of specs with renderer.
+// It renders the pages once to report errors in the template.
+func newDocumentsSite(config DocumentsConfig, renderer Renderer, specs []*servedSpec, switcher func(specBasePath string, selected *servedSpec) []PageRenderer) (*documentsSite, error) {
+	site := &documentsSite{
+		cacheControl: config.CacheControl,
+		renderer:     renderer,
+		specs:        specs,
+		switcher:     switcher,
+		liveReload:   config.LiveReload,
+		modTime:      time.Now(),
+		pages:        newPageCache(),
+	}
+	if r, ok := renderer.(AssetsRenderer); ok {
+		site.assets = r.Assets()
+	}
+	if r, ok := renderer.(SubPathRenderer); ok {
+		site.servesSubPaths = r.ServesSubPaths()
+	}
+
+	for _, s := range specs {
+		if _, err := site.render("/", "/", s); err != nil {
+			return nil, err
+		}
+	}
+	return site, nil
+}
+
+// render renders the page served at basePath. The URLs of the specifications are under specBasePath.
+func (site *documentsSite) render(basePath, specBasePath string, selected *servedSpec) ([]byte, error) {
+	page := Page{
+		BasePath: basePath,
+		SpecUrl:  selected.specUrl(specBasePath),
+		Spec:     selected.specJSON(),
+		Specs:    pageSpecs(site.specs, specBasePath, selected),
+	}
+	if site.switcher != nil {
+		page.Renderers = site.switcher(specBasePath, selected)
+	}
+	if site.liveReload {
+		page.LiveReloadUrl = liveReloadUrl(specBasePath, selected)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := site.renderer.Render(buf, page); err != nil {
+		return nil, &RenderError{Err: err}
+	}
+	return buf.Bytes(), nil
+}
+
+// servePage serves the page, the assets and the redirects of the site at basePath.
+// relPath is the path of the request under basePath.
+func (site *documentsSite) servePage(c echo.Context, basePath, specBasePath, relPath string) error {
+	if site.assets != nil {
+		if name, ok := embeddedAssetName(relPath); ok {
+			return serveEmbeddedAsset(c, site.assets, name)
+		}
+	}
+
+	if !site.servesSubPaths && relPath != "" {
+		// The document site only works with the base path.
+		return redirectKeepingQuery(c, basePath)
+	}
+
+	selected := site.specs[0]
+	key := basePath
+	if selected.name != "" {
+		selected = selectSpec(site.specs, c.QueryParam(specQueryParam))
+		key += "?" + specQueryParam + "=" + selected.name
+	}
+	reloadSpec(c, selected.loadedSpec)
+	// The page of a reloaded specification is rendered again, because a renderer may render the specification itself.
+	key += "#" + selected.etag()
+
+	page, err := site.pages.get(key, func() ([]byte, error) {
+		return site.render(basePath, specBasePath, selected)
+	})
+	if err != nil {
+		return err
+	}
+	modTime := site.modTime
+	if doc := selected.doc.Load(); doc != nil && doc.modTime.After(modTime) {
+		modTime = doc.modTime
+	}
+	return writeCacheable(c, echo.MIMETextHTMLCharsetUTF8, page.body, page.etag, modTime, site.cacheControl)
+}
+
+// redirectKeepingQuery redirects the request to location.
+// The query is kept because it may select the specification to show.
+func redirectKeepingQuery(c echo.Context, location string) error {
+	if q := c.Request().URL.RawQuery; q != "" {
+		location += "?" + q
+	}
+	return c.Redirect(http.StatusFound, location)
+}
+
+// DocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation rendered by renderer.
+// It panics if the configuration is invalid. Use NewDocumentsHandler to handle the error.
+func DocumentsHandler(config DocumentsConfig, renderer Renderer) echo.HandlerFunc {
+	h, err := NewDocumentsHandler(config, renderer)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// Documents registers a handler to serve the OpenAPI documentation rendered by renderer on r.
+// It panics if the configuration is invalid. Use RegisterDocuments to handle the error.
+func Documents(r Router, pathPrefix string, config DocumentsConfig, renderer Renderer) {
+	if err := RegisterDocuments(r, pathPrefix, config, renderer); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterDocuments registers a handler to serve the OpenAPI documentation rendered by renderer on r,
+// which is either *echo.Echo or *echo.Group. The middleware is applied to the handler in addition to the middleware of r.
+// It returns an error if the configuration is invalid.
+func RegisterDocuments(r Router, pathPrefix string, config DocumentsConfig, renderer Renderer, middleware ...echo.MiddlewareFunc) error {
+	return registerDocuments(r, pathPrefix, config, renderer, openAPISpecKind, "Custom", "", middleware)
+}
+
+// registerDocuments registers a handler to serve the documentation of a specification of the kind rendered by renderer on r,
+// and adds the site to the registry of the documentation sites with the name of the generator and the title.
+func registerDocuments(r Router, pathPrefix string, config DocumentsConfig, renderer Renderer, kind specKind, generator, title string, middleware []echo.MiddlewareFunc) error {
+	h, specs, err := newDocumentsHandler(config, renderer, kind)
+	if err != nil {
+		return err
+	}
+	route := r.GET(pathPrefix+"*", h, middleware...)
+	registerDocsSite(route, newDocsSite(route.Path, generator, title, kind, config.PublicBasePath, specs))
+	return nil
+}
@@ -0,0 +1,66 @@
+package openapidocs
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const documentsTestSpec = `openapi: 3.0.0
+info:
+  title: Test
+  version: "1"
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          description: The users.
+`
+
+// newDocumentsBenchmarkEcho returns an echo.Echo that serves the documentation of documentsTestSpec with Redoc at /docs.
+func newDocumentsBenchmarkEcho(b *testing.B) *echo.Echo {
+	b.Helper()
+	e := echo.New()
+	if err := RegisterRedocDocuments(e, "/docs", RedocConfig{
+		Spec:       documentsTestSpec,
+		SiteConfig: SiteConfig{TrustForwardedHeaders: true},
+	}); err != nil {
+		b.Fatal(err)
+	}
+	return e
+}
+
+// BenchmarkDocumentsPageCached serves the page rendered for the same base path, which is cached.
+func BenchmarkDocumentsPageCached(b *testing.B) {
+	e := newDocumentsBenchmarkEcho(b)
+	req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			b.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	}
+}
+
+// BenchmarkDocumentsPageUncached serves the page for a new base path given by X-Forwarded-Prefix on every request,
+// so that the page is rendered every time.
+func BenchmarkDocumentsPageUncached(b *testing.B) {
+	e := newDocumentsBenchmarkEcho(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+		req.Header.Set(headerXForwardedPrefix, fmt.Sprintf("/prefix%d", i))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			b.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package openapidocs
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DriftConfig is the configuration for CheckDrift.
+type DriftConfig struct {
+	// Mode is what CheckDrift does when the routes and the specifications are out of sync.
+	Mode DriftMode
+	// Skip reports whether a route is left out of the check, such as a health check that is not documented on purpose.
+	// The routes of the documentation sites and the index pages registered by this package are always left out.
+	Skip func(route *echo.Route) bool
+}
+
+// DriftMode is what CheckDrift does when the routes and the specifications are out of sync.
+type DriftMode string
+
+const (
+	// DriftModeLog logs the differences as errors with the logger of echo.Echo.
+	DriftModeLog DriftMode = "log"
+	// DriftModeFail returns a *DriftError, so that the server fails to start.
+	DriftModeFail DriftMode = "fail"
+)
+
+var DefaultDriftConfig = DriftConfig{
+	Mode: DriftModeLog,
+	Skip: nil,
+}
+
+// DriftReport is the differences between the routes of echo.Echo and the operations of the OpenAPI specifications
+// of the documentation sites registered on it.
+type DriftReport struct {
+	// Undocumented is the routes that no specification documents.
+	Undocumented []DriftOperation `json:"undocumented"`
+	// Unimplemented is the operations that are documented but have no route.
+	Unimplemented []DriftOperation `json:"unimplemented"`
+}
+
+// DriftOperation is a route or an operation in a DriftReport.
+type DriftOperation struct {
+	// Method is the HTTP method, such as `GET`.
+	Method string `json:"method"`
+	// Path is the path of the route, such as `/users/:id`, or the path of the operation including the base path
+	// of the server, such as `/api/users/{id}`.
+	Path string `json:"path"`
+	// Spec is the documentation site of the operation, such as `/docs` or `/docs?spec=admin`.
+	// It is empty for a route.
+	Spec string `json:"spec,omitempty"`
+}
+
+// InSync reports whether the routes and the specifications are in sync.
+func (r *DriftReport) InSync() bool {
+	return len(r.Undocumented) == 0 && len(r.Unimplemented) == 0
+}
+
+// DriftError is returned by CheckDrift in DriftModeFail when the routes and the specifications are out of sync.
+type DriftError struct {
+	Report *DriftReport
+}
+
+func (e *DriftError) Error() string {
+	return fmt.Sprintf("the routes and the specifications are out of sync: %d undocumented routes, %d unimplemented operations",
+		len(e.Report.Undocumented), len(e.Report.Unimplemented))
+}
+
+// driftReportPath is the path of the drift report under the base path of a documentation site.
+const driftReportPath = "_drift"
+
+// driftMethods is the fields of an OpenAPI path item that are operations.
+var driftMethods = map[string]string{
+	"get":     http.MethodGet,
+	"put":     http.MethodPut,
+	"post":    http.MethodPost,
+	"delete":  http.MethodDelete,
+	"options": http.MethodOptions,
+	"head":    http.MethodHead,
+	"patch":   http.MethodPatch,
+	"trace":   http.MethodTrace,
+}
+
+// CheckDrift compares the routes registered on e with the operations of the OpenAPI specifications of the
+// documentation sites registered on e, and returns the differences.
+// Call it after registering all the routes, before starting the server.
+//
+// A route is undocumented if none of the specifications has its operation, and an operation is unimplemented
+// if there is no route for it. The paths of the operations are prefixed with the path of the first server
+// of the specification, or `basePath` of a Swagger 2.0 document. The names of the path parameters don't matter.
+// The specifications given by SpecUrl are not checked.
+//
+// In DriftModeLog, the differences are logged with e.Logger. In DriftModeFail, a *DriftError is returned
+// if there are differences. An error is also returned if a specification can't be loaded or parsed.
+func CheckDrift(e *echo.Echo, config DriftConfig) (*DriftReport, error) {
+	if config.Mode == "" {
+		config.Mode = DefaultDriftConfig.Mode
+	}
+
+	report, err := newDriftReport(e, config.Skip)
+	if err != nil {
+		return nil, err
+	}
+	switch config.Mode {
+	case DriftModeLog:
+		for _, op := range report.Undocumented {
+			e.Logger.Errorf("openapidocs: undocumented route: %s %s", op.Method, op.Path)
+		}
+		for _, op := range report.Unimplemented {
+			e.Logger.Errorf("openapidocs: unimplemented operation: %s %s in %s", op.Method, op.Path, op.Spec)
+		}
+	case DriftModeFail:
+		if !report.InSync() {
+			return report, &DriftError{Report: report}
+		}
+	default:
+		return nil, fmt.Errorf("unknown drift mode %q", config.Mode)
+	}
+	return report, nil
+}
+
+// serveDriftReport serves the drift report of the echo.Echo that handles the request in JSON.
+func serveDriftReport(c echo.Context) error {
+	report, err := newDriftReport(c.Echo(), nil)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.JSON(http.StatusOK, report)
+}
+
+// driftSpec is the operations of a specification of a documentation site.
+type driftSpec struct {
+	// name is the documentation site of the specification, such as `/docs?spec=admin`.
+	name string
+	// operations is the operations by their normalized method and path, such as `GET /users/{}`.
+	operations map[string]DriftOperation
+}
+
+// newDriftReport returns the drift report of e. Routes are left out if skip reports true.
+func newDriftReport(e *echo.Echo, skip func(route *echo.Route) bool) (*DriftReport, error) {
+	specs, err := driftSpecs(e)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DriftReport{
+		Undocumented:  []DriftOperation{},
+		Unimplemented: []DriftOperation{},
+	}
+	implemented := map[string]bool{}
+	for _, route := range e.Routes() {
+		if !routeSpecMethods[route.Method] || isDocsRoute(route) || (skip != nil && skip(route)) {
+			continue
+		}
+		p, _ := routeSpecPath(route.Path)
+		key := driftKey(route.Method, p)
+		implemented[key] = true
+
+		documented := false
+		for _, s := range specs {
+			if _, ok := s.operations[key]; ok {
+				documented = true
+				break
+			}
+		}
+		if !documented {
+			report.Undocumented = append(report.Undocumented, DriftOperation{Method: route.Method, Path: route.Path})
+		}
+	}
+	for _, s := range specs {
+		for key, op := range s.operations {
+			if !implemented[key] {
+				report.Unimplemented = append(report.Unimplemented, op)
+			}
+		}
+	}
+
+	sortDriftOperations(report.Undocumented)
+	sortDriftOperations(report.Unimplemented)
+	return report, nil
+}
+
+// driftSpecs returns the OpenAPI specifications of the documentation sites registered on e.
+// The current documents served by the sites are used, so that the specifications are not loaded again.
+func driftSpecs(e *echo.Echo) ([]*driftSpec, error) {
+	var specs []*driftSpec
+	for _, site := range registeredDocsSites(e) {
+		if site.kind.path != openAPISpecKind.path {
+			continue
+		}
+		base := strings.TrimSuffix(site.path, "*")
+		for _, s := range site.specs {
+			doc := s.doc.Load()
+			if doc == nil {
+				// The specification is given by SpecUrl.
+				continue
+			}
+			name := base
+			if s.name != "" {
+				name += "?" + specQueryParam + "=" + s.name
+			}
+			if doc.root == nil {
+				return nil, fmt.Errorf("%s: %w", name, errSpecNotParsed)
+			}
+			specs = append(specs, newDriftSpec(name, doc.root))
+		}
+	}
+	return specs, nil
+}
+
+// newDriftSpec returns the operations of the specification whose root node is root.
+func newDriftSpec(name string, root *yaml.Node) *driftSpec {
+	s := &driftSpec{
+		name:       name,
+		operations: map[string]DriftOperation{},
+	}
+	prefix := driftBasePath(root)
+	paths := mappingValue(root, "paths")
+	if paths == nil {
+		return s
+	}
+	keys, items := yamlMappingEntries(paths)
+	for i, key := range keys {
+		p := prefix + key
+		fields, _ := yamlMappingEntries(resolveAlias(items[i]))
+		for _, field := range fields {
+			method, ok := driftMethods[field]
+			if !ok {
+				continue
+			}
+			s.operations[driftKey(method, p)] = DriftOperation{Method: method, Path: p, Spec: name}
+		}
+	}
+	return s
+}
+
+// driftBasePath returns the base path of the operations of the specification, which is the path of the first server
+// of an OpenAPI 3 document or `basePath` of a Swagger 2.0 document, without a trailing slash.
+func driftBasePath(root *yaml.Node) string {
+	var base string
+	if node := mappingValue(root, "basePath"); node != nil {
+		base = node.Value
+	} else if servers := mappingValue(root, "servers"); servers != nil && servers.Kind == yaml.SequenceNode && len(servers.Content) > 0 {
+		if node := mappingValue(resolveAlias(servers.Content[0]), "url"); node != nil && !strings.Contains(node.Value, "{") {
+			if u, err := url.Parse(node.Value); err == nil {
+				base = u.Path
+			}
+		}
+	}
+	return strings.TrimSuffix(base, "/")
+}
+
+var driftParamPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// driftKey returns the key of an operation, in which the path parameters are anonymous and the trailing slash is removed.
+func driftKey(method, pathTemplate string) string {
+	p := driftParamPattern.ReplaceAllString(pathTemplate, "{}")
+	if len(p) > 1 {
+		p = strings.TrimSuffix(p, "/")
+	}
+	return method + " " + p
+}
+
+func sortDriftOperations(ops []DriftOperation) {
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		if ops[i].Method != ops[j].Method {
+			return ops[i].Method < ops[j].Method
+		}
+		return ops[i].Spec < ops[j].Spec
+	})
+}
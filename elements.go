@@ -1,12 +1,10 @@
 package openapidocs
 
 import (
-	"bytes"
 	"github.com/labstack/echo/v4"
 	htmltemplate "html/template"
-	"net/http"
-	"path"
-	"strings"
+	"io"
+	"io/fs"
 )
 
 // ElementsConfig is the configuration for ElementsDocumentsHandler to generate the OpenAPI documentation with Stoplight Elements.
@@ -17,10 +15,24 @@ type ElementsConfig struct {
 	Spec string
 	// SpecUrl is the URL of the OpenAPI specification. If Spec is not empty, SpecUrl is ignored.
 	SpecUrl string
+	// SiteConfig is the configuration of the documentation site that is common to all renderers,
+	// such as the source of the specification and HTTP caching.
+	SiteConfig
 	// Title is the title of the page.
 	Title string
 	// Template is a template string for rendering the page with html/template.
 	Template string
+	// UseEmbeddedAssets makes the page load the Elements JavaScript and CSS files embedded in this package
+	// instead of loading them from a CDN. The files are served under the `_assets` path of the documentation site.
+	UseEmbeddedAssets bool
+	// AssetVersion is the version of Elements to load. The default is the version of the embedded assets.
+	AssetVersion string
+	// ScriptIntegrity is the Subresource Integrity hash of the Elements JavaScript file.
+	// If it is empty, the known hash of AssetVersion is used.
+	ScriptIntegrity string
+	// StylesheetIntegrity is the Subresource Integrity hash of the Elements CSS file.
+	// If it is empty, the known hash of AssetVersion is used.
+	StylesheetIntegrity string
 
 	// Router is the Elements `router` configuration.
 	Router ElementsRouter
@@ -45,7 +57,12 @@ type ElementsConfig struct {
 type elementsTemplateParams struct {
 	ElementsConfig
 	BasePath          string
+	Specs             []PageSpec
+	Renderers         []PageRenderer
+	LiveReloadUrl     string
 	ApiDescriptionUrl string
+	ScriptUrl         string
+	StylesheetUrl     string
 }
 
 type ElementsRouter string
@@ -75,8 +92,13 @@ const (
 var DefaultElementsConfig = ElementsConfig{
 	Spec:                   "",
 	SpecUrl:                "",
+	SiteConfig:             DefaultSiteConfig,
 	Title:                  "API documentation with Stoplight Elements",
 	Template:               defaultElementsTemplate,
+	UseEmbeddedAssets:      false,
+	AssetVersion:           elementsAssets.version,
+	ScriptIntegrity:        "",
+	StylesheetIntegrity:    "",
 	Router:                 ElementsRouterHistory,
 	Layout:                 ElementsLayoutSidebar,
 	HideInternal:           false,
@@ -88,15 +110,22 @@ var DefaultElementsConfig = ElementsConfig{
 	Logo:                   "",
 }
 
+const (
+	elementsScriptFile     = "web-components.min.js"
+	elementsStylesheetFile = "styles.min.css"
+)
+
 const defaultElementsTemplate = `<html lang="en">
 <head>
   <meta charset="utf-8">
   <meta name="viewport" content="width=device-width, initial-scale=1">
   <title>{{ .Title }}</title>
-  <script src="https://unpkg.com/@stoplight/elements/web-components.min.js"></script>
-  <link rel="stylesheet" href="https://unpkg.com/@stoplight/elements/styles.min.css">
+  <script src="{{ .ScriptUrl }}"{{ if .ScriptIntegrity }} integrity="{{ .ScriptIntegrity }}"{{ end }} crossorigin="anonymous"></script>
+  <link rel="stylesheet" href="{{ .StylesheetUrl }}"{{ if .StylesheetIntegrity }} integrity="{{ .StylesheetIntegrity }}"{{ end }} crossorigin="anonymous">
 </head>
 <body>
+  {{- template "renderer-switcher" .Renderers }}
+  {{- template "spec-selector" .Specs }}
   <elements-api
     apiDescriptionUrl="{{ .ApiDescriptionUrl }}"
     {{- if ne .BasePath "" }}
@@ -126,12 +155,23 @@ const defaultElementsTemplate = `<html lang="en">
     {{- end }}
     router="{{ .Router }}"
   />
+  {{- template "live-reload" .LiveReloadUrl }}
 </body>
 </html>
 `
 
-// ElementsDocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with Stoplight Elements.
-func ElementsDocumentsHandler(config ElementsConfig) echo.HandlerFunc {
+// elementsRenderer renders the page of Stoplight Elements.
+type elementsRenderer struct {
+	config ElementsConfig
+	tmpl   *htmltemplate.Template
+	assets fs.FS
+}
+
+// NewElementsRenderer returns a Renderer of the OpenAPI documentation with Stoplight Elements.
+// The specification and the HTTP settings in config, such as Spec and CacheControl, are not used by the renderer.
+// Pass them to Documents in a DocumentsConfig.
+// It returns an error if the configuration is invalid.
+func NewElementsRenderer(config ElementsConfig) (Renderer, error) {
 	if config.Template == "" {
 		config.Template = DefaultElementsConfig.Template
 	}
@@ -147,55 +187,94 @@ func ElementsDocumentsHandler(config ElementsConfig) echo.HandlerFunc {
 	if config.TryItCredentialsPolicy == "" {
 		config.TryItCredentialsPolicy = DefaultElementsConfig.TryItCredentialsPolicy
 	}
+	if config.AssetVersion == "" {
+		config.AssetVersion = DefaultElementsConfig.AssetVersion
+	}
+	var err error
+	if config.ScriptIntegrity, err = elementsAssets.integrityOf(config.AssetVersion, elementsScriptFile, config.ScriptIntegrity, "ScriptIntegrity"); err != nil {
+		return nil, err
+	}
+	if config.StylesheetIntegrity, err = elementsAssets.integrityOf(config.AssetVersion, elementsStylesheetFile, config.StylesheetIntegrity, "StylesheetIntegrity"); err != nil {
+		return nil, err
+	}
 
-	useSpecUrl := false
-	if config.Spec == "" {
-		if config.SpecUrl == "" {
-			panic("either Spec or SpecUrl must be set")
+	var assets fs.FS
+	if config.UseEmbeddedAssets {
+		if assets, err = elementsAssets.embeddedFS(config.AssetVersion, elementsScriptFile, elementsStylesheetFile); err != nil {
+			return nil, err
 		}
-		useSpecUrl = true
 	}
 
-	pageTmpl := htmltemplate.Must(htmltemplate.New("T").Parse(config.Template))
-
-	return func(c echo.Context) error {
-		p := c.Request().URL.Path
+	tmpl, err := parsePageTemplate(config.Template)
+	if err != nil {
+		return nil, err
+	}
 
-		// determine the base path
-		relPath := c.Param("*")
-		basePath := strings.TrimSuffix(p, relPath)
+	return &elementsRenderer{
+		config: config,
+		tmpl:   tmpl,
+		assets: assets,
+	}, nil
+}
 
-		var specUrl string
-		if !useSpecUrl {
-			specUrl = path.Join(basePath, "openapi-spec")
-			if strings.HasSuffix(p, specUrl) {
-				return c.Blob(http.StatusOK, "text/plain; charset=utf-8", []byte(config.Spec))
-			}
-		} else {
-			specUrl = config.SpecUrl
-		}
+func (r *elementsRenderer) Render(w io.Writer, page Page) error {
+	return r.tmpl.Execute(w, elementsTemplateParams{
+		ElementsConfig:    r.config,
+		BasePath:          page.BasePath,
+		Specs:             page.Specs,
+		Renderers:         page.Renderers,
+		LiveReloadUrl:     page.LiveReloadUrl,
+		ApiDescriptionUrl: page.SpecUrl,
+		ScriptUrl:         elementsAssets.url(r.assets, page.BasePath, r.config.AssetVersion, elementsScriptFile),
+		StylesheetUrl:     elementsAssets.url(r.assets, page.BasePath, r.config.AssetVersion, elementsStylesheetFile),
+	})
+}
 
-		if config.Router != ElementsRouterHistory && relPath != "" {
-			// If the router is not history mode, the document site only works with the base path.
-			return c.Redirect(http.StatusFound, basePath)
-		}
+func (r *elementsRenderer) Assets() fs.FS {
+	return r.assets
+}
 
-		params := elementsTemplateParams{
-			ElementsConfig:    config,
-			BasePath:          basePath,
-			ApiDescriptionUrl: specUrl,
-		}
+// ServesSubPaths reports whether Elements uses the history router, which handles the paths under the base path.
+// With the other routers, the documentation site only works with the base path.
+func (r *elementsRenderer) ServesSubPaths() bool {
+	return r.config.Router == ElementsRouterHistory
+}
 
-		buf := new(bytes.Buffer)
-		if err := pageTmpl.Execute(buf, params); err != nil {
-			panic(err)
-		}
+// NewElementsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with Stoplight Elements.
+// It returns an error if the configuration is invalid.
+func NewElementsHandler(config ElementsConfig) (echo.HandlerFunc, error) {
+	renderer, err := NewElementsRenderer(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewDocumentsHandler(DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer)
+}
 
-		return c.HTML(http.StatusOK, buf.String())
+// ElementsDocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with Stoplight Elements.
+// It panics if the configuration is invalid. Use NewElementsHandler to handle the error.
+func ElementsDocumentsHandler(config ElementsConfig) echo.HandlerFunc {
+	h, err := NewElementsHandler(config)
+	if err != nil {
+		panic(err)
 	}
+	return h
 }
 
 // ElementsDocuments registers a handler to serve the OpenAPI documentation with Stoplight Elements.
+// It panics if the configuration is invalid. Use RegisterElementsDocuments to handle the error.
 func ElementsDocuments(e *echo.Echo, pathPrefix string, config ElementsConfig) {
-	e.GET(pathPrefix+"*", ElementsDocumentsHandler(config))
+	if err := RegisterElementsDocuments(e, pathPrefix, config); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterElementsDocuments registers a handler to serve the OpenAPI documentation with Stoplight Elements on r,
+// which is either *echo.Echo or *echo.Group. The middleware is applied to the handler in addition to the middleware of r.
+// It returns an error if the configuration is invalid.
+func RegisterElementsDocuments(r Router, pathPrefix string, config ElementsConfig, middleware ...echo.MiddlewareFunc) error {
+	renderer, err := NewElementsRenderer(config)
+	if err != nil {
+		return err
+	}
+	return registerDocuments(r, pathPrefix, DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer, openAPISpecKind, "Stoplight Elements", config.Title, middleware)
 }
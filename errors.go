@@ -0,0 +1,22 @@
+package openapidocs
+
+import (
+	"errors"
+)
+
+// ErrSpecNotSet is returned when none of Spec, SpecUrl and SpecSource is set in a configuration.
+var ErrSpecNotSet = errors.New("one of Spec, SpecUrl and SpecSource must be set")
+
+// RenderError is returned when rendering a documentation page with the template fails.
+// The handlers return it to echo's HTTPErrorHandler, which responds with 500 Internal Server Error by default.
+type RenderError struct {
+	Err error
+}
+
+func (e *RenderError) Error() string {
+	return "failed to render the documentation page: " + e.Err.Error()
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
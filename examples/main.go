@@ -57,6 +57,65 @@ func main() {
 		Title:   "OpenAI API",
 	})
 
+	// RapiDocDocuments
+	openapidocs.RapiDocDocuments(e, "/docs/rapidoc/github", openapidocs.RapiDocConfig{
+		Spec:  OpenAPISpecGithub,
+		Title: "GitHub v3 REST API",
+	})
+	openapidocs.RapiDocDocuments(e, "/docs/rapidoc/openai", openapidocs.RapiDocConfig{
+		SpecUrl: "https://raw.githubusercontent.com/openai/openai-openapi/master/openapi.yaml",
+		Title:   "OpenAI API",
+	})
+
+	// OpenAPIExplorerDocuments
+	openapidocs.OpenAPIExplorerDocuments(e, "/docs/openapi-explorer/github", openapidocs.OpenAPIExplorerConfig{
+		Spec:  OpenAPISpecGithub,
+		Title: "GitHub v3 REST API",
+	})
+	openapidocs.OpenAPIExplorerDocuments(e, "/docs/openapi-explorer/openai", openapidocs.OpenAPIExplorerConfig{
+		SpecUrl: "https://raw.githubusercontent.com/openai/openai-openapi/master/openapi.yaml",
+		Title:   "OpenAI API",
+	})
+
+	// StaticHTMLDocuments
+	openapidocs.StaticHTMLDocuments(e, "/docs/static-html/github", openapidocs.StaticHTMLConfig{
+		Spec:  OpenAPISpecGithub,
+		Title: "GitHub v3 REST API",
+	})
+
+	// AsyncAPIDocuments
+	openapidocs.AsyncAPIDocuments(e, "/docs/asyncapi/streetlights", openapidocs.AsyncAPIConfig{
+		SpecUrl: "https://raw.githubusercontent.com/asyncapi/spec/master/examples/streetlights-kafka-asyncapi.yml",
+		Title:   "Streetlights Kafka API",
+	})
+
+	// Multiple specs
+	openapidocs.SwaggerUIDocuments(e, "/docs/swagger-ui/multi", openapidocs.SwaggerUIConfig{
+		SiteConfig: openapidocs.SiteConfig{
+			Specs: []openapidocs.NamedSpec{
+				{Name: "github", Title: "GitHub v3 REST API", Spec: OpenAPISpecGithub},
+				{Name: "openai", Title: "OpenAI API", SpecUrl: "https://raw.githubusercontent.com/openai/openai-openapi/master/openapi.yaml"},
+			},
+		},
+	})
+	openapidocs.RedocDocuments(e, "/docs/redoc/multi", openapidocs.RedocConfig{
+		SiteConfig: openapidocs.SiteConfig{
+			Specs: []openapidocs.NamedSpec{
+				{Name: "github", Title: "GitHub v3 REST API", Spec: OpenAPISpecGithub},
+				{Name: "openai", Title: "OpenAI API", SpecUrl: "https://raw.githubusercontent.com/openai/openai-openapi/master/openapi.yaml"},
+			},
+		},
+	})
+
+	// CombinedDocuments
+	openapidocs.CombinedDocuments(e, "/docs/combined/github", openapidocs.CombinedConfig{
+		Spec:  OpenAPISpecGithub,
+		Title: "GitHub v3 REST API",
+	})
+
+	// DocsIndex lists all the documentation above
+	openapidocs.DocsIndex(e, "/docs")
+
 	// Start the server
 	if err := e.Start(":8080"); err != nil {
 		log.Fatal(err)
@@ -1,10 +1,14 @@
 module github.com/kohkimakimoto/echo-openapidocs
 
-go 1.23.0
+go 1.24.0
 
 toolchain go1.24.5
 
-require github.com/labstack/echo/v4 v4.13.4
+require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/labstack/echo/v4 v4.13.4
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/labstack/gommon v0.4.2 // indirect
@@ -0,0 +1,65 @@
+package openapidocs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultCacheControl is the default Cache-Control header value for the documentation pages and the OpenAPI specifications.
+// Clients may store the responses but must revalidate them with the ETag before reusing them.
+const defaultCacheControl = "no-cache"
+
+// computeETag returns a strong ETag of data.
+func computeETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeCacheable writes data with the cache validators and the Cache-Control header.
+// If the conditional headers of the request match the validators, it responds with 304 Not Modified instead.
+func writeCacheable(c echo.Context, contentType string, data []byte, etag string, lastModified time.Time, cacheControl string) error {
+	h := c.Response().Header()
+	h.Set("ETag", etag)
+	if !lastModified.IsZero() {
+		h.Set(echo.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+	if cacheControl != "" {
+		h.Set(echo.HeaderCacheControl, cacheControl)
+	}
+
+	if notModified(c.Request(), etag, lastModified) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+// notModified evaluates the If-None-Match and If-Modified-Since headers of the request.
+// If-Modified-Since is ignored when If-None-Match is present, as RFC 9110 requires.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, t := range strings.Split(inm, ",") {
+			t = strings.TrimSpace(t)
+			if t == "*" || strings.TrimPrefix(t, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get(echo.HeaderIfModifiedSince); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+// Command assetsgen downloads pinned builds of the documentation generators' JavaScript and CSS files
+// into the assets directory, which is embedded into the openapidocs package.
+// It also writes the Subresource Integrity hashes of the files into assets_integrity.go.
+//
+// It is run by `go generate` in the root directory of the repository.
+package main
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// bundle is a set of files of an npm package to be embedded.
+type bundle struct {
+	// Dir is the directory under the assets directory.
+	Dir string
+	// Package is the npm package name.
+	Package string
+	// Version is the pinned version of the npm package.
+	// Keep it in sync with the asset bundles in assets.go.
+	Version string
+	// Files are the paths of the files in the npm package.
+	// Each file is stored with its base name.
+	Files []string
+}
+
+var bundles = []bundle{
+	{
+		Dir:     "elements",
+		Package: "@stoplight/elements",
+		Version: "8.1.0",
+		Files:   []string{"web-components.min.js", "styles.min.css"},
+	},
+	{
+		Dir:     "scalar",
+		Package: "@scalar/api-reference",
+		Version: "1.25.0",
+		Files:   []string{"dist/browser/standalone.js"},
+	},
+	{
+		Dir:     "swagger-ui",
+		Package: "swagger-ui-dist",
+		Version: "5.17.14",
+		Files:   []string{"swagger-ui.css", "swagger-ui-bundle.js", "swagger-ui-standalone-preset.js"},
+	},
+	{
+		Dir:     "redoc",
+		Package: "redoc",
+		Version: "2.1.5",
+		Files:   []string{"bundles/redoc.standalone.js"},
+	},
+	{
+		Dir:     "rapidoc",
+		Package: "rapidoc",
+		Version: "9.3.8",
+		Files:   []string{"dist/rapidoc-min.js"},
+	},
+	{
+		Dir:     "openapi-explorer",
+		Package: "openapi-explorer",
+		Version: "2.2.733",
+		Files:   []string{"dist/browser/openapi-explorer.min.js"},
+	},
+	{
+		Dir:     "asyncapi",
+		Package: "@asyncapi/web-component",
+		Version: "1.4.10",
+		Files:   []string{"lib/asyncapi-web-component.js"},
+	},
+	{
+		Dir:     "asyncapi",
+		Package: "@asyncapi/react-component",
+		Version: "1.4.10",
+		Files:   []string{"styles/default.min.css"},
+	},
+}
+
+const cdnBaseUrl = "https://cdn.jsdelivr.net/npm"
+
+// integrityFile is the Go file of the Subresource Integrity hashes of the files.
+const integrityFile = "assets_integrity.go"
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("assetsgen: ")
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	integrities := map[string]string{}
+	for _, b := range bundles {
+		for _, file := range b.Files {
+			url := fmt.Sprintf("%s/%s@%s/%s", cdnBaseUrl, b.Package, b.Version, file)
+			dst := filepath.Join("assets", b.Dir, filepath.Base(file))
+			if err := download(client, url, dst); err != nil {
+				log.Fatal(err)
+			}
+			hash, err := integrity(dst)
+			if err != nil {
+				log.Fatal(err)
+			}
+			integrities[path.Join(b.Dir, b.Version, path.Base(file))] = hash
+			log.Printf("%s -> %s", url, dst)
+		}
+	}
+	if err := writeIntegrities(integrityFile, integrities); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("hashes -> %s", integrityFile)
+}
+
+func download(client *http.Client, url, dst string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// integrity returns the Subresource Integrity hash of the file.
+func integrity(name string) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	sum := sha512.Sum384(data)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:]), nil
+}
+
+// writeIntegrities writes the hashes by the directory, version and name of the files into the Go file dst.
+func writeIntegrities(dst string, integrities map[string]string) error {
+	keys := make([]string, 0, len(integrities))
+	for key := range integrities {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by internal/assetsgen. DO NOT EDIT.\n\n")
+	buf.WriteString("package openapidocs\n\n")
+	buf.WriteString("// assetIntegrities is the Subresource Integrity hashes of the embedded assets by their directory, version and name,\n")
+	buf.WriteString("// such as `swagger-ui/5.17.14/swagger-ui-bundle.js`.\n")
+	buf.WriteString("var assetIntegrities = map[string]string{\n")
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "\t%q: %q,\n", key, integrities[key])
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, src, 0o644)
+}
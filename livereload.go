@@ -0,0 +1,93 @@
+package openapidocs
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+// liveReloadPath is the path of the Server-Sent Events endpoint of the live reload under the base path.
+const liveReloadPath = "_live-reload"
+
+// liveReloadHashParam is the query parameter of the live reload endpoint that has the hash of the specification
+// shown on the page.
+const liveReloadHashParam = "hash"
+
+// liveReloadKeepAlive is the interval of the comments sent to keep the live reload connection open.
+const liveReloadKeepAlive = 15 * time.Second
+
+// liveReloadTemplate is the live reload script of a page.
+// Page templates render it with `{{ template "live-reload" .LiveReloadUrl }}`. It renders nothing if the live reload is disabled.
+// The endpoint sends a `reload` event when the specification differs from the one shown on the page.
+const liveReloadTemplate = `
+{{- if . }}
+<script>
+  new EventSource({{ . }}).addEventListener("reload", function () { location.reload(); });
+</script>
+{{- end }}`
+
+// liveReloadUrl returns the URL of the live reload endpoint for the page of selected served at basePath.
+// It returns an empty string if the specification is not served by the site.
+func liveReloadUrl(basePath string, selected *servedSpec) string {
+	if !selected.served() {
+		return ""
+	}
+	q := url.Values{}
+	if selected.name != "" {
+		q.Set(specQueryParam, selected.name)
+	}
+	q.Set(liveReloadHashParam, selected.etag())
+	return path.Join(basePath, liveReloadPath) + "?" + q.Encode()
+}
+
+// serveLiveReload streams the Server-Sent Events of the live reload for the specification selected by the request.
+// It reloads the specification while the connection is open, and sends a `reload` event with the new hash
+// when the specification differs from the one shown on the page. The client reconnects after reloading the page.
+func serveLiveReload(c echo.Context, specs []*servedSpec) error {
+	s := selectSpec(specs, c.QueryParam(specQueryParam))
+	if !s.served() {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+	hash := c.QueryParam(liveReloadHashParam)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-store")
+	// Disable the response buffering of nginx.
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprint(res, "retry: 1000\n\n"); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	ticker := time.NewTicker(specWatchInterval)
+	defer ticker.Stop()
+	lastWrite := time.Now()
+	for {
+		reloadSpec(c, s.loadedSpec)
+		if etag := s.etag(); etag != hash {
+			if _, err := fmt.Fprintf(res, "event: reload\ndata: %s\n\n", etag); err != nil {
+				return nil
+			}
+			res.Flush()
+			return nil
+		}
+
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case now := <-ticker.C:
+			if now.Sub(lastWrite) >= liveReloadKeepAlive {
+				if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+					return nil
+				}
+				res.Flush()
+				lastWrite = now
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package openapidocs
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"regexp"
+)
+
+// NamedSpec is one of the specifications of a documentation site that hosts several specifications.
+type NamedSpec struct {
+	// Name identifies the specification. The specification is served at `<prefix>/openapi-spec/<name>`,
+	// or `<prefix>/asyncapi-spec/<name>` for the AsyncAPI documentation,
+	// so Name may contain only letters, digits, `-`, `_` and `.`.
+	Name string
+	// Title is the label of the specification in the definition selector. If it is empty, Name is used.
+	Title string
+	// Spec is the specification.
+	Spec string
+	// SpecUrl is the URL of the specification. If Spec is not empty, SpecUrl is ignored.
+	SpecUrl string
+	// SpecSource is the source of the specification, such as a file. If it is not nil, Spec and SpecUrl are ignored.
+	SpecSource SpecSource
+	// SharedSpec is a specification shared with other documentation sites and the validation middlewares.
+	// If it is not nil, Spec, SpecUrl and SpecSource are ignored.
+	SharedSpec *SharedSpec
+}
+
+// PageSpec is a specification listed on a page of a documentation site that hosts several specifications.
+type PageSpec struct {
+	// Name is the name of the specification.
+	Name string
+	// Title is the label of the specification.
+	Title string
+	// Url is the URL of the specification.
+	Url string
+	// Selected reports whether the page shows the specification.
+	Selected bool
+}
+
+// specQueryParam is the query parameter that selects the specification shown on a documentation site
+// that hosts several specifications.
+const specQueryParam = "spec"
+
+// specSelectorTemplate is the definition selector of a documentation site that hosts several specifications.
+// Page templates render it with `{{ template "spec-selector" .Specs }}`. It renders nothing for a single specification.
+// The selector is a form, so it also works without JavaScript.
+const specSelectorTemplate = `
+{{- if . }}
+<form class="spec-selector" method="get" style="margin: 0; padding: 0.5rem 1rem; border-bottom: 1px solid #d0d7de; font-family: system-ui, sans-serif; font-size: 14px;">
+  <label for="spec-selector">API definition</label>
+  <select id="spec-selector" name="spec" onchange="this.form.submit()">
+    {{- range . }}
+    <option value="{{ .Name }}"{{ if .Selected }} selected{{ end }}>{{ .Title }}</option>
+    {{- end }}
+  </select>
+  <noscript><button type="submit">Show</button></noscript>
+</form>
+{{- end }}`
+
+// parsePageTemplate parses the template of a page.
+// The template can use the `spec-selector`, `renderer-switcher` and `live-reload` templates.
+func parsePageTemplate(text string) (*htmltemplate.Template, error) {
+	tmpl := htmltemplate.New("T")
+	if _, err := tmpl.New("spec-selector").Parse(specSelectorTemplate); err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.New("renderer-switcher").Parse(rendererSwitcherTemplate); err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.New("live-reload").Parse(liveReloadTemplate); err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.Parse(text); err != nil {
+		return nil, fmt.Errorf("failed to parse the template: %w", err)
+	}
+	return tmpl, nil
+}
+
+var specNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// findSpecEndpoint returns the specification served at relPath and the format requested by the path.
+func findSpecEndpoint(specs []*servedSpec, relPath string) (*servedSpec, specFormat, bool) {
+	for _, s := range specs {
+		if !s.served() {
+			continue
+		}
+		if format, ok := specEndpoint(relPath, s.path); ok {
+			return s, format, true
+		}
+	}
+	return nil, specFormatUnknown, false
+}
+
+// selectSpec returns the specification named name, or the first one if there is no such specification.
+func selectSpec(specs []*servedSpec, name string) *servedSpec {
+	for _, s := range specs {
+		if s.name != "" && s.name == name {
+			return s
+		}
+	}
+	return specs[0]
+}
+
+// pageSpecs returns the list of the specifications shown on a page served at basePath, or nil if the site
+// hosts a single specification.
+func pageSpecs(specs []*servedSpec, basePath string, selected *servedSpec) []PageSpec {
+	if len(specs) == 1 && specs[0].name == "" {
+		return nil
+	}
+	list := make([]PageSpec, 0, len(specs))
+	for _, s := range specs {
+		title := s.title
+		if title == "" {
+			title = s.name
+		}
+		list = append(list, PageSpec{
+			Name:     s.name,
+			Title:    title,
+			Url:      s.specUrl(basePath),
+			Selected: s == selected,
+		})
+	}
+	return list
+}
@@ -0,0 +1,241 @@
+package openapidocs
+
+import (
+	"github.com/labstack/echo/v4"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+)
+
+// OpenAPIExplorerConfig is the configuration for OpenAPIExplorerDocumentsHandler to generate the OpenAPI documentation with OpenAPI Explorer.
+// Some fields are OpenAPI Explorer configuration options.
+// See https://github.com/Authress-Engineering/openapi-explorer
+type OpenAPIExplorerConfig struct {
+	// Spec is the OpenAPI specification.
+	Spec string
+	// SpecUrl is the URL of the OpenAPI specification. If Spec is not empty, SpecUrl is ignored.
+	SpecUrl string
+	// SiteConfig is the configuration of the documentation site that is common to all renderers,
+	// such as the source of the specification and HTTP caching.
+	SiteConfig
+	// Title is the title of the page.
+	Title string
+	// Template is a template string for rendering the page with html/template.
+	Template string
+	// UseEmbeddedAssets makes the page load the OpenAPI Explorer JavaScript file embedded in this package
+	// instead of loading it from a CDN. The file is served under the `_assets` path of the documentation site.
+	UseEmbeddedAssets bool
+	// AssetVersion is the version of OpenAPI Explorer to load. The default is the version of the embedded assets.
+	AssetVersion string
+	// ScriptIntegrity is the Subresource Integrity hash of the OpenAPI Explorer JavaScript file.
+	// If it is empty, the known hash of AssetVersion is used.
+	ScriptIntegrity string
+
+	// ServerUrl is the OpenAPI Explorer `server-url` configuration.
+	ServerUrl string
+	// HideServerSelection is the OpenAPI Explorer `hide-server-selection` configuration.
+	HideServerSelection bool
+	// HideAuthentication is the OpenAPI Explorer `hide-authentication` configuration.
+	HideAuthentication bool
+	// HideConsole is the OpenAPI Explorer `hide-console` configuration.
+	HideConsole bool
+	// Collapse is the OpenAPI Explorer `collapse` configuration.
+	Collapse bool
+	// SchemaExpandLevel is the OpenAPI Explorer `schema-expand-level` configuration.
+	SchemaExpandLevel int
+	// Table is the OpenAPI Explorer `table` configuration.
+	Table bool
+	// NavItemSpacing is the OpenAPI Explorer `nav-item-spacing` configuration.
+	NavItemSpacing OpenAPIExplorerNavItemSpacing
+	// UsePathInNavBar is the OpenAPI Explorer `use-path-in-nav-bar` configuration.
+	UsePathInNavBar bool
+	// HideComponents is the OpenAPI Explorer `hide-components` configuration.
+	HideComponents bool
+}
+
+type openAPIExplorerTemplateParams struct {
+	OpenAPIExplorerConfig
+	BasePath      string
+	Specs         []PageSpec
+	LiveReloadUrl string
+	SpecUrl       string
+	ScriptUrl     string
+}
+
+type OpenAPIExplorerNavItemSpacing string
+
+const (
+	OpenAPIExplorerNavItemSpacingDefault OpenAPIExplorerNavItemSpacing = "default"
+	OpenAPIExplorerNavItemSpacingCompact OpenAPIExplorerNavItemSpacing = "compact"
+	OpenAPIExplorerNavItemSpacingRelaxed OpenAPIExplorerNavItemSpacing = "relaxed"
+)
+
+var DefaultOpenAPIExplorerConfig = OpenAPIExplorerConfig{
+	Spec:                "",
+	SpecUrl:             "",
+	SiteConfig:          DefaultSiteConfig,
+	Title:               "API documentation with OpenAPI Explorer",
+	Template:            defaultOpenAPIExplorerTemplate,
+	UseEmbeddedAssets:   false,
+	AssetVersion:        openAPIExplorerAssets.version,
+	ScriptIntegrity:     "",
+	ServerUrl:           "",
+	HideServerSelection: false,
+	HideAuthentication:  false,
+	HideConsole:         false,
+	Collapse:            false,
+	SchemaExpandLevel:   0,
+	Table:               false,
+	NavItemSpacing:      OpenAPIExplorerNavItemSpacingDefault,
+	UsePathInNavBar:     false,
+	HideComponents:      false,
+}
+
+const openAPIExplorerScriptFile = "openapi-explorer.min.js"
+
+const defaultOpenAPIExplorerTemplate = `<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Title }}</title>
+  <script type="module" src="{{ .ScriptUrl }}"{{ if .ScriptIntegrity }} integrity="{{ .ScriptIntegrity }}"{{ end }} crossorigin="anonymous"></script>
+</head>
+<body>
+  {{- template "spec-selector" .Specs }}
+  <openapi-explorer
+    spec-url="{{ .SpecUrl }}"
+    {{- if ne .ServerUrl "" }}
+    server-url="{{ .ServerUrl }}"
+    {{- end }}
+    {{- if .HideServerSelection }}
+    hide-server-selection="true"
+    {{- end }}
+    {{- if .HideAuthentication }}
+    hide-authentication="true"
+    {{- end }}
+    {{- if .HideConsole }}
+    hide-console="true"
+    {{- end }}
+    {{- if .Collapse }}
+    collapse="true"
+    {{- end }}
+    {{- if ne .SchemaExpandLevel 0 }}
+    schema-expand-level="{{ .SchemaExpandLevel }}"
+    {{- end }}
+    {{- if .Table }}
+    table="true"
+    {{- end }}
+    nav-item-spacing="{{ .NavItemSpacing }}"
+    {{- if .UsePathInNavBar }}
+    use-path-in-nav-bar="true"
+    {{- end }}
+    {{- if .HideComponents }}
+    hide-components="true"
+    {{- end }}
+  ></openapi-explorer>
+  {{- template "live-reload" .LiveReloadUrl }}
+</body>
+</html>
+`
+
+// openAPIExplorerRenderer renders the page of OpenAPI Explorer.
+type openAPIExplorerRenderer struct {
+	config OpenAPIExplorerConfig
+	tmpl   *htmltemplate.Template
+	assets fs.FS
+}
+
+// NewOpenAPIExplorerRenderer returns a Renderer of the OpenAPI documentation with OpenAPI Explorer.
+// The specification and the HTTP settings in config, such as Spec and CacheControl, are not used by the renderer.
+// Pass them to Documents in a DocumentsConfig.
+// It returns an error if the configuration is invalid.
+func NewOpenAPIExplorerRenderer(config OpenAPIExplorerConfig) (Renderer, error) {
+	if config.Template == "" {
+		config.Template = DefaultOpenAPIExplorerConfig.Template
+	}
+	if config.Title == "" {
+		config.Title = DefaultOpenAPIExplorerConfig.Title
+	}
+	if config.NavItemSpacing == "" {
+		config.NavItemSpacing = DefaultOpenAPIExplorerConfig.NavItemSpacing
+	}
+	if config.AssetVersion == "" {
+		config.AssetVersion = DefaultOpenAPIExplorerConfig.AssetVersion
+	}
+	var err error
+	if config.ScriptIntegrity, err = openAPIExplorerAssets.integrityOf(config.AssetVersion, openAPIExplorerScriptFile, config.ScriptIntegrity, "ScriptIntegrity"); err != nil {
+		return nil, err
+	}
+
+	var assets fs.FS
+	if config.UseEmbeddedAssets {
+		if assets, err = openAPIExplorerAssets.embeddedFS(config.AssetVersion, openAPIExplorerScriptFile); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpl, err := parsePageTemplate(config.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openAPIExplorerRenderer{
+		config: config,
+		tmpl:   tmpl,
+		assets: assets,
+	}, nil
+}
+
+func (r *openAPIExplorerRenderer) Render(w io.Writer, page Page) error {
+	return r.tmpl.Execute(w, openAPIExplorerTemplateParams{
+		OpenAPIExplorerConfig: r.config,
+		BasePath:              page.BasePath,
+		Specs:                 page.Specs,
+		LiveReloadUrl:         page.LiveReloadUrl,
+		SpecUrl:               page.SpecUrl,
+		ScriptUrl:             openAPIExplorerAssets.url(r.assets, page.BasePath, r.config.AssetVersion, openAPIExplorerScriptFile),
+	})
+}
+
+func (r *openAPIExplorerRenderer) Assets() fs.FS {
+	return r.assets
+}
+
+// NewOpenAPIExplorerHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with OpenAPI Explorer.
+// It returns an error if the configuration is invalid.
+func NewOpenAPIExplorerHandler(config OpenAPIExplorerConfig) (echo.HandlerFunc, error) {
+	renderer, err := NewOpenAPIExplorerRenderer(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewDocumentsHandler(DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer)
+}
+
+// OpenAPIExplorerDocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with OpenAPI Explorer.
+// It panics if the configuration is invalid. Use NewOpenAPIExplorerHandler to handle the error.
+func OpenAPIExplorerDocumentsHandler(config OpenAPIExplorerConfig) echo.HandlerFunc {
+	h, err := NewOpenAPIExplorerHandler(config)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// OpenAPIExplorerDocuments registers a handler to serve the OpenAPI documentation with OpenAPI Explorer.
+// It panics if the configuration is invalid. Use RegisterOpenAPIExplorerDocuments to handle the error.
+func OpenAPIExplorerDocuments(e *echo.Echo, pathPrefix string, config OpenAPIExplorerConfig) {
+	if err := RegisterOpenAPIExplorerDocuments(e, pathPrefix, config); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterOpenAPIExplorerDocuments registers a handler to serve the OpenAPI documentation with OpenAPI Explorer on r,
+// which is either *echo.Echo or *echo.Group. The middleware is applied to the handler in addition to the middleware of r.
+// It returns an error if the configuration is invalid.
+func RegisterOpenAPIExplorerDocuments(r Router, pathPrefix string, config OpenAPIExplorerConfig, middleware ...echo.MiddlewareFunc) error {
+	renderer, err := NewOpenAPIExplorerRenderer(config)
+	if err != nil {
+		return err
+	}
+	return registerDocuments(r, pathPrefix, DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer, openAPISpecKind, "OpenAPI Explorer", config.Title, middleware)
+}
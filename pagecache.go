@@ -0,0 +1,63 @@
+package openapidocs
+
+import (
+	"sync"
+)
+
+// maxCachedPages is the maximum number of rendered pages a handler keeps.
+// A handler usually serves its page under only a few base paths, so the limit just bounds the memory.
+const maxCachedPages = 64
+
+// renderedPage is a documentation page rendered for a base path and a selected specification.
+type renderedPage struct {
+	body []byte
+	etag string
+}
+
+// pageCache caches the rendered pages by base path and selected specification.
+// It is safe for concurrent use. When it is full, the oldest page is evicted.
+type pageCache struct {
+	mu    sync.RWMutex
+	pages map[string]*renderedPage
+	// keys is the keys of the pages in the order they are added.
+	keys []string
+}
+
+func newPageCache() *pageCache {
+	return &pageCache{
+		pages: map[string]*renderedPage{},
+	}
+}
+
+// get returns the page rendered for key. If the page is not cached yet, it renders the page with render.
+func (pc *pageCache) get(key string, render func() ([]byte, error)) (*renderedPage, error) {
+	pc.mu.RLock()
+	page, ok := pc.pages[key]
+	pc.mu.RUnlock()
+	if ok {
+		return page, nil
+	}
+
+	body, err := render()
+	if err != nil {
+		return nil, err
+	}
+	page = &renderedPage{
+		body: body,
+		etag: computeETag(body),
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if cached, ok := pc.pages[key]; ok {
+		// Another request has rendered the page in the meantime.
+		return cached, nil
+	}
+	if len(pc.keys) >= maxCachedPages {
+		delete(pc.pages, pc.keys[0])
+		pc.keys = pc.keys[1:]
+	}
+	pc.pages[key] = page
+	pc.keys = append(pc.keys, key)
+	return page, nil
+}
@@ -0,0 +1,121 @@
+package openapidocs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestPageCacheGet(t *testing.T) {
+	pc := newPageCache()
+	renders := 0
+	render := func() ([]byte, error) {
+		renders++
+		return []byte("page"), nil
+	}
+
+	first, err := pc.get("/docs", render)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := pc.get("/docs", render)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("get() returned another page for the cached key")
+	}
+	if renders != 1 {
+		t.Errorf("renders = %d, want 1", renders)
+	}
+	if first.etag != computeETag([]byte("page")) {
+		t.Errorf("etag = %q, want the etag of the body", first.etag)
+	}
+
+	errRender := errors.New("render error")
+	if _, err := pc.get("/broken", func() ([]byte, error) { return nil, errRender }); !errors.Is(err, errRender) {
+		t.Errorf("err = %v, want %v", err, errRender)
+	}
+	if _, ok := pc.pages["/broken"]; ok {
+		t.Error("the page that failed to render is cached")
+	}
+}
+
+func TestPageCacheEvictsOldestPage(t *testing.T) {
+	pc := newPageCache()
+	rendered := map[string]int{}
+	get := func(key string) {
+		t.Helper()
+		if _, err := pc.get(key, func() ([]byte, error) {
+			rendered[key]++
+			return []byte(key), nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i <= maxCachedPages; i++ {
+		get(fmt.Sprintf("/docs%d", i))
+	}
+	if len(pc.pages) != maxCachedPages || len(pc.keys) != maxCachedPages {
+		t.Fatalf("cached pages = %d, keys = %d, want %d", len(pc.pages), len(pc.keys), maxCachedPages)
+	}
+
+	tests := []struct {
+		key         string
+		wantRenders int
+	}{
+		// The oldest page is evicted, and rendered again.
+		{key: "/docs0", wantRenders: 2},
+		// The second oldest page is evicted by the page rendered again.
+		{key: "/docs1", wantRenders: 2},
+		// The newest pages are kept.
+		{key: fmt.Sprintf("/docs%d", maxCachedPages), wantRenders: 1},
+		{key: "/docs0", wantRenders: 2},
+	}
+	for _, tt := range tests {
+		get(tt.key)
+		if rendered[tt.key] != tt.wantRenders {
+			t.Errorf("renders of %s = %d, want %d", tt.key, rendered[tt.key], tt.wantRenders)
+		}
+	}
+}
+
+func TestPageCacheConcurrentGet(t *testing.T) {
+	pc := newPageCache()
+	const goroutines = 32
+	pages := make([]*renderedPage, goroutines)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			// Other keys are added and evicted in the meantime.
+			for j := 0; j < maxCachedPages/goroutines; j++ {
+				key := fmt.Sprintf("/docs%d-%d", i, j)
+				if _, err := pc.get(key, func() ([]byte, error) { return []byte(key), nil }); err != nil {
+					t.Error(err)
+				}
+			}
+			page, err := pc.get("/docs", func() ([]byte, error) { return []byte("page"), nil })
+			if err != nil {
+				t.Error(err)
+			}
+			pages[i] = page
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	for i, page := range pages {
+		if page != pages[0] {
+			t.Errorf("page %d differs from the page of the other goroutines", i)
+		}
+	}
+	if len(pc.pages) != len(pc.keys) || len(pc.pages) > maxCachedPages {
+		t.Errorf("cached pages = %d, keys = %d, want at most %d", len(pc.pages), len(pc.keys), maxCachedPages)
+	}
+}
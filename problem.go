@@ -0,0 +1,81 @@
+package openapidocs
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// mimeApplicationProblemJSON is the content type of a problem details object.
+const mimeApplicationProblemJSON = "application/problem+json"
+
+// Problem is a problem details object of RFC 7807, which the validation middleware responds with.
+type Problem struct {
+	// Type is a URI that identifies the problem type. It is `about:blank` for the problems of the validation.
+	Type string `json:"type"`
+	// Title is the summary of the problem type, which is the text of the status code.
+	Title string `json:"title"`
+	// Status is the HTTP status code.
+	Status int `json:"status"`
+	// Detail is the explanation of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance is the path of the request.
+	Instance string `json:"instance,omitempty"`
+	// Errors is the values that don't match the specification. It is an extension member of the problem.
+	Errors []ProblemError `json:"errors,omitempty"`
+}
+
+// ProblemError is a value that doesn't match the specification.
+type ProblemError struct {
+	// In is the location of the value, which is `path`, `query`, `header`, `cookie`, `body`, or `status` for a response.
+	In string `json:"in"`
+	// Name is the name of the parameter or the header. It is empty for the body.
+	Name string `json:"name,omitempty"`
+	// Pointer is the JSON pointer of the invalid value in the body or the parameter, such as `/items/0/name`.
+	Pointer string `json:"pointer,omitempty"`
+	// Message is the description of the error.
+	Message string `json:"message"`
+}
+
+func (p *Problem) Error() string {
+	msg := p.Title
+	if p.Detail != "" {
+		msg += ": " + p.Detail
+	}
+	for _, e := range p.Errors {
+		msg += "; " + e.String()
+	}
+	return msg
+}
+
+func (e ProblemError) String() string {
+	s := e.In
+	if e.Name != "" {
+		s += " " + e.Name
+	}
+	if e.Pointer != "" {
+		s += " " + e.Pointer
+	}
+	return s + ": " + e.Message
+}
+
+// newProblem returns a problem of the request with the status code.
+func newProblem(c echo.Context, status int, detail string, errs []ProblemError) *Problem {
+	return &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request().URL.Path,
+		Errors:   errs,
+	}
+}
+
+// writeProblem writes the problem as the response.
+func writeProblem(c echo.Context, p *Problem) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.Blob(p.Status, mimeApplicationProblemJSON, data)
+}
@@ -0,0 +1,261 @@
+package openapidocs
+
+import (
+	"github.com/labstack/echo/v4"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+)
+
+// RapiDocConfig is the configuration for RapiDocDocumentsHandler to generate the OpenAPI documentation with RapiDoc.
+// Some fields are RapiDoc configuration options.
+// See https://rapidocweb.com/api.html
+type RapiDocConfig struct {
+	// Spec is the OpenAPI specification.
+	Spec string
+	// SpecUrl is the URL of the OpenAPI specification. If Spec is not empty, SpecUrl is ignored.
+	SpecUrl string
+	// SiteConfig is the configuration of the documentation site that is common to all renderers,
+	// such as the source of the specification and HTTP caching.
+	SiteConfig
+	// Title is the title of the page.
+	Title string
+	// Template is a template string for rendering the page with html/template.
+	Template string
+	// UseEmbeddedAssets makes the page load the RapiDoc JavaScript file embedded in this package
+	// instead of loading it from a CDN. The file is served under the `_assets` path of the documentation site.
+	UseEmbeddedAssets bool
+	// AssetVersion is the version of RapiDoc to load. The default is the version of the embedded assets.
+	AssetVersion string
+	// ScriptIntegrity is the Subresource Integrity hash of the RapiDoc JavaScript file.
+	// If it is empty, the known hash of AssetVersion is used.
+	ScriptIntegrity string
+
+	// Theme is the RapiDoc `theme` configuration.
+	Theme RapiDocTheme
+	// RenderStyle is the RapiDoc `render-style` configuration.
+	RenderStyle RapiDocRenderStyle
+	// Layout is the RapiDoc `layout` configuration.
+	Layout RapiDocLayout
+	// SchemaStyle is the RapiDoc `schema-style` configuration.
+	SchemaStyle RapiDocSchemaStyle
+	// AllowTry is the RapiDoc `allow-try` configuration.
+	AllowTry RapiDocAllowTry
+	// HideHeader hides the header. It is the negation of the RapiDoc `show-header` configuration.
+	HideHeader bool
+	// HeadingText is the RapiDoc `heading-text` configuration.
+	HeadingText string
+	// PrimaryColor is the RapiDoc `primary-color` configuration.
+	PrimaryColor string
+}
+
+type rapiDocTemplateParams struct {
+	RapiDocConfig
+	BasePath      string
+	Specs         []PageSpec
+	LiveReloadUrl string
+	SpecUrl       string
+	ScriptUrl     string
+}
+
+type RapiDocTheme string
+
+const (
+	RapiDocThemeLight RapiDocTheme = "light"
+	RapiDocThemeDark  RapiDocTheme = "dark"
+)
+
+type RapiDocRenderStyle string
+
+const (
+	RapiDocRenderStyleRead    RapiDocRenderStyle = "read"
+	RapiDocRenderStyleView    RapiDocRenderStyle = "view"
+	RapiDocRenderStyleFocused RapiDocRenderStyle = "focused"
+)
+
+type RapiDocLayout string
+
+const (
+	RapiDocLayoutRow    RapiDocLayout = "row"
+	RapiDocLayoutColumn RapiDocLayout = "column"
+)
+
+type RapiDocSchemaStyle string
+
+const (
+	RapiDocSchemaStyleTree  RapiDocSchemaStyle = "tree"
+	RapiDocSchemaStyleTable RapiDocSchemaStyle = "table"
+)
+
+type RapiDocAllowTry string
+
+const (
+	RapiDocAllowTryTrue  RapiDocAllowTry = "true"
+	RapiDocAllowTryFalse RapiDocAllowTry = "false"
+)
+
+var DefaultRapiDocConfig = RapiDocConfig{
+	Spec:              "",
+	SpecUrl:           "",
+	SiteConfig:        DefaultSiteConfig,
+	Title:             "API documentation with RapiDoc",
+	Template:          defaultRapiDocTemplate,
+	UseEmbeddedAssets: false,
+	AssetVersion:      rapiDocAssets.version,
+	ScriptIntegrity:   "",
+	Theme:             RapiDocThemeLight,
+	RenderStyle:       RapiDocRenderStyleView,
+	Layout:            RapiDocLayoutRow,
+	SchemaStyle:       RapiDocSchemaStyleTree,
+	AllowTry:          RapiDocAllowTryTrue,
+	HideHeader:        false,
+	HeadingText:       "",
+	PrimaryColor:      "",
+}
+
+const rapiDocScriptFile = "rapidoc-min.js"
+
+const defaultRapiDocTemplate = `<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Title }}</title>
+  <script type="module" src="{{ .ScriptUrl }}"{{ if .ScriptIntegrity }} integrity="{{ .ScriptIntegrity }}"{{ end }} crossorigin="anonymous"></script>
+</head>
+<body>
+  {{- template "spec-selector" .Specs }}
+  <rapi-doc
+    spec-url="{{ .SpecUrl }}"
+    theme="{{ .Theme }}"
+    render-style="{{ .RenderStyle }}"
+    layout="{{ .Layout }}"
+    schema-style="{{ .SchemaStyle }}"
+    allow-try="{{ .AllowTry }}"
+    {{- if .HideHeader }}
+    show-header="false"
+    {{- end }}
+    {{- if ne .HeadingText "" }}
+    heading-text="{{ .HeadingText }}"
+    {{- end }}
+    {{- if ne .PrimaryColor "" }}
+    primary-color="{{ .PrimaryColor }}"
+    {{- end }}
+  ></rapi-doc>
+  {{- template "live-reload" .LiveReloadUrl }}
+</body>
+</html>
+`
+
+// rapiDocRenderer renders the page of RapiDoc.
+type rapiDocRenderer struct {
+	config RapiDocConfig
+	tmpl   *htmltemplate.Template
+	assets fs.FS
+}
+
+// NewRapiDocRenderer returns a Renderer of the OpenAPI documentation with RapiDoc.
+// The specification and the HTTP settings in config, such as Spec and CacheControl, are not used by the renderer.
+// Pass them to Documents in a DocumentsConfig.
+// It returns an error if the configuration is invalid.
+func NewRapiDocRenderer(config RapiDocConfig) (Renderer, error) {
+	if config.Template == "" {
+		config.Template = DefaultRapiDocConfig.Template
+	}
+	if config.Title == "" {
+		config.Title = DefaultRapiDocConfig.Title
+	}
+	if config.Theme == "" {
+		config.Theme = DefaultRapiDocConfig.Theme
+	}
+	if config.RenderStyle == "" {
+		config.RenderStyle = DefaultRapiDocConfig.RenderStyle
+	}
+	if config.Layout == "" {
+		config.Layout = DefaultRapiDocConfig.Layout
+	}
+	if config.SchemaStyle == "" {
+		config.SchemaStyle = DefaultRapiDocConfig.SchemaStyle
+	}
+	if config.AllowTry == "" {
+		config.AllowTry = DefaultRapiDocConfig.AllowTry
+	}
+	if config.AssetVersion == "" {
+		config.AssetVersion = DefaultRapiDocConfig.AssetVersion
+	}
+	var err error
+	if config.ScriptIntegrity, err = rapiDocAssets.integrityOf(config.AssetVersion, rapiDocScriptFile, config.ScriptIntegrity, "ScriptIntegrity"); err != nil {
+		return nil, err
+	}
+
+	var assets fs.FS
+	if config.UseEmbeddedAssets {
+		if assets, err = rapiDocAssets.embeddedFS(config.AssetVersion, rapiDocScriptFile); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpl, err := parsePageTemplate(config.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rapiDocRenderer{
+		config: config,
+		tmpl:   tmpl,
+		assets: assets,
+	}, nil
+}
+
+func (r *rapiDocRenderer) Render(w io.Writer, page Page) error {
+	return r.tmpl.Execute(w, rapiDocTemplateParams{
+		RapiDocConfig: r.config,
+		BasePath:      page.BasePath,
+		Specs:         page.Specs,
+		LiveReloadUrl: page.LiveReloadUrl,
+		SpecUrl:       page.SpecUrl,
+		ScriptUrl:     rapiDocAssets.url(r.assets, page.BasePath, r.config.AssetVersion, rapiDocScriptFile),
+	})
+}
+
+func (r *rapiDocRenderer) Assets() fs.FS {
+	return r.assets
+}
+
+// NewRapiDocHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with RapiDoc.
+// It returns an error if the configuration is invalid.
+func NewRapiDocHandler(config RapiDocConfig) (echo.HandlerFunc, error) {
+	renderer, err := NewRapiDocRenderer(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewDocumentsHandler(DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer)
+}
+
+// RapiDocDocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with RapiDoc.
+// It panics if the configuration is invalid. Use NewRapiDocHandler to handle the error.
+func RapiDocDocumentsHandler(config RapiDocConfig) echo.HandlerFunc {
+	h, err := NewRapiDocHandler(config)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// RapiDocDocuments registers a handler to serve the OpenAPI documentation with RapiDoc.
+// It panics if the configuration is invalid. Use RegisterRapiDocDocuments to handle the error.
+func RapiDocDocuments(e *echo.Echo, pathPrefix string, config RapiDocConfig) {
+	if err := RegisterRapiDocDocuments(e, pathPrefix, config); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterRapiDocDocuments registers a handler to serve the OpenAPI documentation with RapiDoc on r,
+// which is either *echo.Echo or *echo.Group. The middleware is applied to the handler in addition to the middleware of r.
+// It returns an error if the configuration is invalid.
+func RegisterRapiDocDocuments(r Router, pathPrefix string, config RapiDocConfig, middleware ...echo.MiddlewareFunc) error {
+	renderer, err := NewRapiDocRenderer(config)
+	if err != nil {
+		return err
+	}
+	return registerDocuments(r, pathPrefix, DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer, openAPISpecKind, "RapiDoc", config.Title, middleware)
+}
@@ -1,12 +1,10 @@
 package openapidocs
 
 import (
-	"bytes"
 	"github.com/labstack/echo/v4"
 	htmltemplate "html/template"
-	"net/http"
-	"path"
-	"strings"
+	"io"
+	"io/fs"
 )
 
 // RedocConfig is the configuration for RedocDocumentsHandler to generate the OpenAPI documentation with Redoc.
@@ -17,10 +15,21 @@ type RedocConfig struct {
 	Spec string
 	// SpecUrl is the URL of the OpenAPI specification. If Spec is not empty, SpecUrl is ignored.
 	SpecUrl string
+	// SiteConfig is the configuration of the documentation site that is common to all renderers,
+	// such as the source of the specification and HTTP caching.
+	SiteConfig
 	// Title is the title of the page.
 	Title string
 	// Template is a template string for rendering the page with html/template.
 	Template string
+	// UseEmbeddedAssets makes the page load the Redoc JavaScript file embedded in this package
+	// instead of loading it from a CDN. The file is served under the `_assets` path of the documentation site.
+	UseEmbeddedAssets bool
+	// AssetVersion is the version of Redoc to load. The default is the version of the embedded assets.
+	AssetVersion string
+	// ScriptIntegrity is the Subresource Integrity hash of the Redoc JavaScript file.
+	// If it is empty, the known hash of AssetVersion is used.
+	ScriptIntegrity string
 
 	// DisableSearch is the Redoc `disableSearch` configuration.
 	DisableSearch bool
@@ -32,18 +41,28 @@ type RedocConfig struct {
 
 type redocTemplateParams struct {
 	RedocConfig
-	BasePath string
-	SpecUrl  string
+	BasePath      string
+	Specs         []PageSpec
+	Renderers     []PageRenderer
+	LiveReloadUrl string
+	SpecUrl       string
+	ScriptUrl     string
 }
 
 var DefaultRedocConfig = RedocConfig{
 	Spec:                           "",
 	SpecUrl:                        "",
+	SiteConfig:                     DefaultSiteConfig,
 	Title:                          "API documentation with Redoc",
 	Template:                       defaultRedocTemplate,
+	UseEmbeddedAssets:              false,
+	AssetVersion:                   redocAssets.version,
+	ScriptIntegrity:                "",
 	MinCharacterLengthToInitSearch: 0,
 }
 
+const redocScriptFile = "redoc.standalone.js"
+
 const defaultRedocTemplate = `<html lang="en">
 <head>
   <meta charset="utf-8">
@@ -51,6 +70,8 @@ const defaultRedocTemplate = `<html lang="en">
   <title>{{ .Title }}</title>
 </head>
 <body>
+  {{- template "renderer-switcher" .Renderers }}
+  {{- template "spec-selector" .Specs }}
   <redoc
     spec-url="{{ .SpecUrl }}"
     {{- if .DisableSearch }}
@@ -60,66 +81,108 @@ const defaultRedocTemplate = `<html lang="en">
 	min-character-length-to-init-search="{{ .MinCharacterLengthToInitSearch }}"
 	{{- end }}
   ></redoc>
-  <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"> </script>
+  <script src="{{ .ScriptUrl }}"{{ if .ScriptIntegrity }} integrity="{{ .ScriptIntegrity }}"{{ end }} crossorigin="anonymous"> </script>
+  {{- template "live-reload" .LiveReloadUrl }}
 </body>
 </html>
 `
 
-func RedocDocumentsHandler(config RedocConfig) echo.HandlerFunc {
+// redocRenderer renders the page of Redoc.
+type redocRenderer struct {
+	config RedocConfig
+	tmpl   *htmltemplate.Template
+	assets fs.FS
+}
+
+// NewRedocRenderer returns a Renderer of the OpenAPI documentation with Redoc.
+// The specification and the HTTP settings in config, such as Spec and CacheControl, are not used by the renderer.
+// Pass them to Documents in a DocumentsConfig.
+// It returns an error if the configuration is invalid.
+func NewRedocRenderer(config RedocConfig) (Renderer, error) {
 	if config.Template == "" {
 		config.Template = DefaultRedocConfig.Template
 	}
 	if config.Title == "" {
 		config.Title = DefaultRedocConfig.Title
 	}
+	if config.AssetVersion == "" {
+		config.AssetVersion = DefaultRedocConfig.AssetVersion
+	}
+	var err error
+	if config.ScriptIntegrity, err = redocAssets.integrityOf(config.AssetVersion, redocScriptFile, config.ScriptIntegrity, "ScriptIntegrity"); err != nil {
+		return nil, err
+	}
 
-	useSpecUrl := false
-	if config.Spec == "" {
-		if config.SpecUrl == "" {
-			panic("either Spec or SpecUrl must be set")
+	var assets fs.FS
+	if config.UseEmbeddedAssets {
+		if assets, err = redocAssets.embeddedFS(config.AssetVersion, redocScriptFile); err != nil {
+			return nil, err
 		}
-		useSpecUrl = true
 	}
 
-	pageTmpl := htmltemplate.Must(htmltemplate.New("T").Parse(config.Template))
-
-	return func(c echo.Context) error {
-		p := c.Request().URL.Path
-
-		// determine the base path
-		relPath := c.Param("*")
-		basePath := strings.TrimSuffix(p, relPath)
+	tmpl, err := parsePageTemplate(config.Template)
+	if err != nil {
+		return nil, err
+	}
 
-		var specUrl string
-		if !useSpecUrl {
-			specUrl = path.Join(basePath, "openapi-spec")
-			if strings.HasSuffix(p, specUrl) {
-				return c.Blob(http.StatusOK, "text/plain; charset=utf-8", []byte(config.Spec))
-			}
-		} else {
-			specUrl = config.SpecUrl
-		}
+	return &redocRenderer{
+		config: config,
+		tmpl:   tmpl,
+		assets: assets,
+	}, nil
+}
 
-		if relPath != "" {
-			// The document site only works with the base path.
-			return c.Redirect(http.StatusFound, basePath)
-		}
+func (r *redocRenderer) Render(w io.Writer, page Page) error {
+	return r.tmpl.Execute(w, redocTemplateParams{
+		RedocConfig:   r.config,
+		BasePath:      page.BasePath,
+		Specs:         page.Specs,
+		Renderers:     page.Renderers,
+		LiveReloadUrl: page.LiveReloadUrl,
+		SpecUrl:       page.SpecUrl,
+		ScriptUrl:     redocAssets.url(r.assets, page.BasePath, r.config.AssetVersion, redocScriptFile),
+	})
+}
 
-		params := redocTemplateParams{
-			RedocConfig: config,
-			BasePath:    basePath,
-			SpecUrl:     specUrl,
-		}
+func (r *redocRenderer) Assets() fs.FS {
+	return r.assets
+}
 
-		buf := new(bytes.Buffer)
-		if err := pageTmpl.Execute(buf, params); err != nil {
-			panic(err)
-		}
+// NewRedocHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with Redoc.
+// It returns an error if the configuration is invalid.
+func NewRedocHandler(config RedocConfig) (echo.HandlerFunc, error) {
+	renderer, err := NewRedocRenderer(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewDocumentsHandler(DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer)
+}
 
-		return c.HTML(http.StatusOK, buf.String())
+// RedocDocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with Redoc.
+// It panics if the configuration is invalid. Use NewRedocHandler to handle the error.
+func RedocDocumentsHandler(config RedocConfig) echo.HandlerFunc {
+	h, err := NewRedocHandler(config)
+	if err != nil {
+		panic(err)
 	}
+	return h
 }
 
+// RedocDocuments registers a handler to serve the OpenAPI documentation with Redoc.
+// It panics if the configuration is invalid. Use RegisterRedocDocuments to handle the error.
 func RedocDocuments(e *echo.Echo, pathPrefix string, config RedocConfig) {
-	e.GET(pathPrefix+"*", RedocDocumentsHandler(config))
+	if err := RegisterRedocDocuments(e, pathPrefix, config); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterRedocDocuments registers a handler to serve the OpenAPI documentation with Redoc on r,
+// which is either *echo.Echo or *echo.Group. The middleware is applied to the handler in addition to the middleware of r.
+// It returns an error if the configuration is invalid.
+func RegisterRedocDocuments(r Router, pathPrefix string, config RedocConfig, middleware ...echo.MiddlewareFunc) error {
+	renderer, err := NewRedocRenderer(config)
+	if err != nil {
+		return err
+	}
+	return registerDocuments(r, pathPrefix, DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer, openAPISpecKind, "Redoc", config.Title, middleware)
 }
@@ -0,0 +1,114 @@
+package openapidocs
+
+import (
+	"github.com/labstack/echo/v4"
+	"runtime"
+	"sort"
+	"sync"
+	"weak"
+)
+
+// routeState is what this package knows about a route registered on an echo.Echo.
+type routeState struct {
+	// seq is the order in which the state of the route was set.
+	seq uint64
+	// site is the documentation site served by the route, or nil.
+	site *docsSite
+	// index reports whether the route serves an index page registered by RegisterDocsIndex.
+	index bool
+	// doc is the documentation of the route set by DescribeRoute, or nil.
+	doc *RouteDoc
+}
+
+// routeStates is the state of the routes by route.
+// The routes are held weakly, and the state of a route is removed when the route is garbage collected,
+// so the state doesn't keep an echo.Echo or its documentation sites alive.
+// The state of the routes of an echo.Echo is found by walking its routes, which works for the routes
+// registered on an echo.Group as well, because echo.Group doesn't tell its echo.Echo.
+// The state must not refer to its route, or the route is never garbage collected.
+var routeStates struct {
+	mu     sync.Mutex
+	seq    uint64
+	states map[weak.Pointer[echo.Route]]*routeState
+}
+
+// updateRouteState calls update with the state of route, which is created if route has no state.
+func updateRouteState(route *echo.Route, update func(state *routeState)) {
+	routeStates.mu.Lock()
+	defer routeStates.mu.Unlock()
+	key := weak.Make(route)
+	state := routeStates.states[key]
+	if state == nil {
+		if routeStates.states == nil {
+			routeStates.states = map[weak.Pointer[echo.Route]]*routeState{}
+		}
+		routeStates.seq++
+		state = &routeState{seq: routeStates.seq}
+		routeStates.states[key] = state
+		runtime.AddCleanup(route, deleteRouteState, key)
+	}
+	update(state)
+}
+
+// deleteRouteState removes the state of the route of key. It is called when the route is garbage collected.
+func deleteRouteState(key weak.Pointer[echo.Route]) {
+	routeStates.mu.Lock()
+	defer routeStates.mu.Unlock()
+	delete(routeStates.states, key)
+}
+
+// routeStateOf returns a copy of the state of route. It returns false if route has no state.
+func routeStateOf(route *echo.Route) (routeState, bool) {
+	routeStates.mu.Lock()
+	defer routeStates.mu.Unlock()
+	state := routeStates.states[weak.Make(route)]
+	if state == nil {
+		return routeState{}, false
+	}
+	return *state, true
+}
+
+// routeStatesOf returns the states of the routes of e in the order in which they were set.
+func routeStatesOf(e *echo.Echo) []routeState {
+	var states []routeState
+	for _, route := range e.Routes() {
+		if state, ok := routeStateOf(route); ok {
+			states = append(states, state)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].seq < states[j].seq
+	})
+	return states
+}
+
+// registerDocsSite sets site as the documentation site served by route.
+func registerDocsSite(route *echo.Route, site *docsSite) {
+	updateRouteState(route, func(state *routeState) {
+		state.site = site
+	})
+}
+
+// registerDocsIndex marks route as the route of an index page.
+func registerDocsIndex(route *echo.Route) {
+	updateRouteState(route, func(state *routeState) {
+		state.index = true
+	})
+}
+
+// registeredDocsSites returns the documentation sites registered on e in the order in which they were registered.
+func registeredDocsSites(e *echo.Echo) []*docsSite {
+	var sites []*docsSite
+	for _, state := range routeStatesOf(e) {
+		if state.site != nil {
+			sites = append(sites, state.site)
+		}
+	}
+	return sites
+}
+
+// isDocsRoute reports whether route is the route of a documentation site or an index page registered by this package.
+func isDocsRoute(route *echo.Route) bool {
+	state, ok := routeStateOf(route)
+	return ok && (state.site != nil || state.index)
+}
@@ -0,0 +1,88 @@
+package openapidocs
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"weak"
+)
+
+func TestRegisteredDocsSites(t *testing.T) {
+	e := echo.New()
+	if err := RegisterRedocDocuments(e, "/docs", RedocConfig{Spec: documentsTestSpec}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterScalarDocuments(e.Group("/api"), "/docs", ScalarConfig{Spec: documentsTestSpec}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterDocsIndex(e, "/", DefaultDocsIndexConfig); err != nil {
+		t.Fatal(err)
+	}
+	users := e.GET("/users", func(c echo.Context) error { return nil })
+
+	other := echo.New()
+	if err := RegisterRedocDocuments(other, "/other-docs", RedocConfig{Spec: documentsTestSpec}); err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, site := range registeredDocsSites(e) {
+		paths = append(paths, site.path)
+	}
+	if want := []string{"/docs*", "/api/docs*"}; strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("sites = %v, want %v", paths, want)
+	}
+	if got := registeredDocsSites(other); len(got) != 1 || got[0].path != "/other-docs*" {
+		t.Errorf("sites of the other echo = %v, want /other-docs*", got)
+	}
+
+	for _, route := range e.Routes() {
+		want := route != users
+		if got := isDocsRoute(route); got != want {
+			t.Errorf("isDocsRoute(%s %s) = %v, want %v", route.Method, route.Path, got, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	for _, link := range []string{`href="/docs"`, `href="/api/docs"`} {
+		if !strings.Contains(rec.Body.String(), link) {
+			t.Errorf("the index page doesn't link to %s", link)
+		}
+	}
+	if strings.Contains(rec.Body.String(), "/other-docs") {
+		t.Error("the index page lists the site of the other echo")
+	}
+}
+
+func TestRouteStateIsReleasedWithEcho(t *testing.T) {
+	key := func() weak.Pointer[echo.Route] {
+		e := echo.New()
+		if err := RegisterRedocDocuments(e.Group("/api"), "/docs", RedocConfig{Spec: documentsTestSpec}); err != nil {
+			t.Fatal(err)
+		}
+		return weak.Make(e.Routes()[0])
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		runtime.GC()
+		routeStates.mu.Lock()
+		_, ok := routeStates.states[key]
+		routeStates.mu.Unlock()
+		if !ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the state of the route is kept after its echo is discarded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
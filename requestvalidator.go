@@ -0,0 +1,295 @@
+package openapidocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// RequestValidatorConfig is the configuration for the request validation middleware.
+type RequestValidatorConfig struct {
+	// Skipper reports whether the validation is skipped for the request.
+	Skipper func(c echo.Context) bool
+	// Spec is the OpenAPI specification that the requests are validated against. It must be in JSON or YAML.
+	Spec string
+	// SpecSource is the source of the specification, which is used instead of Spec.
+	SpecSource SpecSource
+	// WatchSpec reloads the specification from SpecSource when it changes.
+	WatchSpec bool
+	// SharedSpec is a specification shared with the documentation sites and the other validation middleware,
+	// so that it is loaded and reloaded once. If it is not nil, Spec, SpecSource and WatchSpec are ignored.
+	SharedSpec *SharedSpec
+	// MaxBodySize is the maximum size of the request body in bytes that is read for the validation.
+	// A larger body is answered with a problem of `413 Request Entity Too Large`. The default is 10 MB.
+	MaxBodySize int64
+	// ErrorHandler handles the problem of a request that doesn't match the specification.
+	// By default, the problem is written as the response in `application/problem+json`.
+	ErrorHandler func(c echo.Context, problem *Problem) error
+}
+
+var DefaultRequestValidatorConfig = RequestValidatorConfig{
+	Skipper:      nil,
+	Spec:         "",
+	SpecSource:   nil,
+	WatchSpec:    false,
+	SharedSpec:   nil,
+	MaxBodySize:  10 << 20,
+	ErrorHandler: writeProblem,
+}
+
+// requestProblemDetail is the detail of the problems of the requests that don't match the specification.
+const requestProblemDetail = "The request doesn't match the API specification."
+
+// ignoredHeaderParams is the header parameters that are ignored by OpenAPI.
+var ignoredHeaderParams = map[string]bool{
+	echo.HeaderAccept:        true,
+	echo.HeaderContentType:   true,
+	echo.HeaderAuthorization: true,
+}
+
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// NewRequestValidator returns a middleware that validates the requests against the operations of the specification.
+//
+// The operation of a request is found by the path of its echo route and its method, in the same way as CheckDrift.
+// The path, query, header and cookie parameters and the JSON request body are validated against their schemas.
+// A request that doesn't match is answered with a problem of RFC 7807: `400 Bad Request`, or
+// `415 Unsupported Media Type` if the content type of the body is not declared, or `413 Request Entity Too Large`
+// if the body is larger than MaxBodySize. The requests of the routes that the specification doesn't have are passed through.
+func NewRequestValidator(config RequestValidatorConfig) (echo.MiddlewareFunc, error) {
+	if config.MaxBodySize == 0 {
+		config.MaxBodySize = DefaultRequestValidatorConfig.MaxBodySize
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultRequestValidatorConfig.ErrorHandler
+	}
+	if config.MaxBodySize < 0 {
+		return nil, fmt.Errorf("the max body size must not be negative: %d", config.MaxBodySize)
+	}
+
+	spec, err := validatedSpecOf(config.SharedSpec, config.Spec, config.SpecSource, config.WatchSpec)
+	if err != nil {
+		return nil, err
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+			ops := spec.operations(c)
+			op := ops.find(c)
+			if op == nil {
+				return next(c)
+			}
+			problem, err := validateRequest(c, ops, op, config.MaxBodySize)
+			if err != nil {
+				return err
+			}
+			if problem != nil {
+				return config.ErrorHandler(c, problem)
+			}
+			return next(c)
+		}
+	}, nil
+}
+
+// RequestValidator is like NewRequestValidator, but panics if an error occurs.
+func RequestValidator(config RequestValidatorConfig) echo.MiddlewareFunc {
+	m, err := NewRequestValidator(config)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// validateRequest validates the request against op, and returns the problem if it doesn't match.
+func validateRequest(c echo.Context, ops *specOperations, op *specOperation, maxBodySize int64) (*Problem, error) {
+	errs := validateParameters(c, ops, op)
+	if op.requestBody != nil {
+		bodyErrs, status, err := validateRequestBody(c, ops, op.requestBody, maxBodySize)
+		if err != nil {
+			return nil, err
+		}
+		if status == http.StatusUnsupportedMediaType || status == http.StatusRequestEntityTooLarge {
+			return newProblem(c, status, requestProblemDetail, bodyErrs), nil
+		}
+		errs = append(errs, bodyErrs...)
+	}
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	return newProblem(c, http.StatusBadRequest, requestProblemDetail, errs), nil
+}
+
+// validateParameters validates the parameters of the request against the parameters of op.
+func validateParameters(c echo.Context, ops *specOperations, op *specOperation) []ProblemError {
+	var errs []ProblemError
+	req := c.Request()
+	pathValues := op.pathParamValues(c)
+	for _, p := range op.parameters {
+		var values []string
+		switch p.in {
+		case "path":
+			if v, ok := pathValues[p.name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			values = c.QueryParams()[p.name]
+		case "header":
+			if ignoredHeaderParams[http.CanonicalHeaderKey(p.name)] {
+				continue
+			}
+			values = req.Header.Values(p.name)
+		case "cookie":
+			if cookie, err := req.Cookie(p.name); err == nil {
+				values = []string{cookie.Value}
+			}
+		default:
+			continue
+		}
+		if len(values) == 0 {
+			if p.required {
+				errs = append(errs, ProblemError{In: p.in, Name: p.name, Message: "is required"})
+			}
+			continue
+		}
+		value := ops.parameterValue(p, values)
+		for _, v := range ops.validator.validate(p.schema, value, "", schemaDirectionRequest) {
+			errs = append(errs, ProblemError{In: p.in, Name: p.name, Pointer: v.pointer, Message: v.message})
+		}
+	}
+	return errs
+}
+
+// parameterValue converts the values of the parameter into a JSON value by the type of its schema.
+func (ops *specOperations) parameterValue(p *specParameter, values []string) any {
+	schema := ops.validator.deref(p.schema)
+	if !hasSchemaType(schema, "array") {
+		return ops.parameterItemValue(schema, values[0])
+	}
+
+	var items []string
+	if p.explode && p.in == "query" {
+		items = values
+	} else {
+		for _, v := range values {
+			for _, item := range strings.Split(v, p.separator) {
+				if p.in == "header" {
+					item = strings.TrimSpace(item)
+				}
+				items = append(items, item)
+			}
+		}
+	}
+	itemSchema := ops.validator.deref(schema["items"])
+	array := make([]any, 0, len(items))
+	for _, item := range items {
+		array = append(array, ops.parameterItemValue(itemSchema, item))
+	}
+	return array
+}
+
+// parameterItemValue converts a value of a parameter into a number or a boolean if the schema allows it.
+// The value is left as a string otherwise, so that it is reported as the wrong type.
+func (ops *specOperations) parameterItemValue(schema map[string]any, value string) any {
+	for _, t := range schemaTypes(schema) {
+		switch t {
+		case "integer", "number":
+			if jsonNumberPattern.MatchString(value) {
+				return json.Number(value)
+			}
+		case "boolean":
+			if value == "true" || value == "false" {
+				return value == "true"
+			}
+		}
+	}
+	return value
+}
+
+// hasSchemaType reports whether the schema allows the type.
+func hasSchemaType(schema map[string]any, t string) bool {
+	for _, st := range schemaTypes(schema) {
+		if st == t {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRequestBody validates the request body against the request body object.
+// The status is http.StatusUnsupportedMediaType if the content type of the body is not declared,
+// http.StatusRequestEntityTooLarge if the body is larger than maxBodySize, http.StatusBadRequest if the body
+// doesn't match otherwise, or 0 if it matches.
+// The body is restored so that the handler can read it.
+func validateRequestBody(c echo.Context, ops *specOperations, requestBody map[string]any, maxBodySize int64) ([]ProblemError, int, error) {
+	req := c.Request()
+	var data []byte
+	if req.Body != nil {
+		var err error
+		if data, err = io.ReadAll(http.MaxBytesReader(c.Response(), req.Body, maxBodySize)); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return []ProblemError{{In: "body", Message: fmt.Sprintf("must not be larger than %d bytes", maxBytesErr.Limit)}},
+					http.StatusRequestEntityTooLarge, nil
+			}
+			return nil, 0, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	if len(data) == 0 {
+		if required, _ := requestBody["required"].(bool); required {
+			return []ProblemError{{In: "body", Message: "is required"}}, http.StatusBadRequest, nil
+		}
+		return nil, 0, nil
+	}
+
+	content, _ := requestBody["content"].(map[string]any)
+	if len(content) == 0 {
+		return nil, 0, nil
+	}
+	contentType := req.Header.Get(echo.HeaderContentType)
+	mediaType, _, ok := findMediaType(content, contentType)
+	if !ok {
+		return []ProblemError{{In: "body", Message: fmt.Sprintf("content type %q is not supported", contentType)}},
+			http.StatusUnsupportedMediaType, nil
+	}
+	schema, ok := mediaType["schema"]
+	if !ok || !isJSONMediaType(contentType) {
+		return nil, 0, nil
+	}
+
+	value, err := decodeJSONValue(data)
+	if err != nil {
+		return []ProblemError{{In: "body", Message: "is not valid JSON: " + err.Error()}}, http.StatusBadRequest, nil
+	}
+	var errs []ProblemError
+	for _, v := range ops.validator.validate(schema, value, "", schemaDirectionRequest) {
+		errs = append(errs, ProblemError{In: "body", Pointer: v.pointer, Message: v.message})
+	}
+	if len(errs) == 0 {
+		return nil, 0, nil
+	}
+	return errs, http.StatusBadRequest, nil
+}
+
+// decodeJSONValue decodes a single JSON value, keeping the numbers as json.Number.
+func decodeJSONValue(data []byte) (any, error) {
+	var value any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the value")
+	}
+	return value, nil
+}
@@ -0,0 +1,407 @@
+package openapidocs
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const requestValidatorTestSpec = `openapi: 3.0.3
+info:
+  title: Test
+  version: "1"
+paths:
+  /items/{itemId}:
+    parameters:
+      - {name: itemId, in: path, required: true, schema: {type: integer}}
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, minimum: 1}}
+        - {name: tags, in: query, schema: {type: array, items: {type: string, maxLength: 3}}}
+        - {name: ids, in: query, explode: false, schema: {type: array, items: {type: integer}}}
+        - {name: names, in: query, style: pipeDelimited, explode: false, schema: {type: array, items: {type: string, minLength: 2}}}
+        - {name: flag, in: query, schema: {type: boolean}}
+        - {name: X-Ids, in: header, required: true, schema: {type: array, items: {type: integer}}}
+        - {name: Accept, in: header, required: true, schema: {type: string}}
+        - {name: session, in: cookie, schema: {$ref: '#/components/schemas/Session'}}
+        - {name: itemId, in: path, required: true, schema: {type: integer, minimum: 10}}
+      responses:
+        "200":
+          description: The item.
+  /users:
+    post:
+      requestBody:
+        $ref: '#/components/requestBodies/User'
+      responses:
+        "201":
+          description: The user.
+components:
+  schemas:
+    Session: {type: string, minLength: 3}
+    User:
+      type: object
+      required: [id, name]
+      properties:
+        id: {type: integer, readOnly: true}
+        name: {type: string}
+  requestBodies:
+    User:
+      required: true
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/User'
+        text/*: {}
+`
+
+const requestValidatorTestSwaggerSpec = `swagger: "2.0"
+info:
+  title: Test
+  version: "1"
+basePath: /v1
+consumes: [application/json]
+paths:
+  /items:
+    post:
+      parameters:
+        - {name: ids, in: query, type: array, items: {type: integer}, collectionFormat: pipes}
+        - {name: tags, in: query, type: array, items: {type: string, maxLength: 3}, collectionFormat: multi}
+        - {name: limit, in: query, required: true, type: integer, maximum: 10}
+        - {name: body, in: body, required: true, schema: {$ref: '#/definitions/Item'}}
+        - {name: file, in: formData, required: true, type: file}
+      responses:
+        "200":
+          description: The item.
+definitions:
+  Item:
+    type: object
+    required: [name]
+    properties:
+      name: {type: string}
+`
+
+// newTestSpecOperations returns the operations of the specification in YAML.
+func newTestSpecOperations(t *testing.T, spec string) *specOperations {
+	t.Helper()
+	doc, err := newSpecDocument(spec, validateSpecNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, err := newSpecOperations(doc.json.body, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ops
+}
+
+// newRequestValidatorTestContext returns the context of req routed to the echo route path with the path parameter values.
+func newRequestValidatorTestContext(req *http.Request, routePath string, values ...string) echo.Context {
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	c.SetPath(routePath)
+	var names []string
+	for _, segment := range strings.Split(routePath, "/") {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			names = append(names, name)
+		}
+	}
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+	return c
+}
+
+func TestValidateParameters(t *testing.T) {
+	ops := newTestSpecOperations(t, requestValidatorTestSpec)
+	tests := []struct {
+		name   string
+		itemId string
+		query  string
+		xIds   string
+		cookie string
+		want   []ProblemError
+	}{
+		{name: "valid", itemId: "10", query: "limit=1&tags=a&tags=b&ids=1,2&names=ab|cd&flag=true", xIds: "1, 2", cookie: "abc"},
+		{name: "missing required header", itemId: "10", want: []ProblemError{{In: "header", Name: "X-Ids", Message: "is required"}}},
+		{name: "path parameter", itemId: "x", xIds: "1", want: []ProblemError{{In: "path", Name: "itemId", Message: "must be integer"}}},
+		{name: "overridden path parameter", itemId: "5", xIds: "1", want: []ProblemError{{In: "path", Name: "itemId", Message: "must be >= 10"}}},
+		{name: "query parameter", itemId: "10", query: "limit=0", xIds: "1", want: []ProblemError{{In: "query", Name: "limit", Message: "must be >= 1"}}},
+		{
+			name:   "exploded array",
+			itemId: "10",
+			query:  "tags=a&tags=abcd",
+			xIds:   "1",
+			want:   []ProblemError{{In: "query", Name: "tags", Pointer: "/1", Message: "must be at most 3 characters long"}},
+		},
+		{name: "comma-separated array", itemId: "10", query: "ids=1,x", xIds: "1", want: []ProblemError{{In: "query", Name: "ids", Pointer: "/1", Message: "must be integer"}}},
+		{
+			name:   "pipe-delimited array",
+			itemId: "10",
+			query:  "names=ab|c",
+			xIds:   "1",
+			want:   []ProblemError{{In: "query", Name: "names", Pointer: "/1", Message: "must be at least 2 characters long"}},
+		},
+		{name: "boolean", itemId: "10", query: "flag=yes", xIds: "1", want: []ProblemError{{In: "query", Name: "flag", Message: "must be boolean"}}},
+		{name: "header array", itemId: "10", xIds: "1, x", want: []ProblemError{{In: "header", Name: "X-Ids", Pointer: "/1", Message: "must be integer"}}},
+		{
+			name:   "cookie with a referenced schema",
+			itemId: "10",
+			xIds:   "1",
+			cookie: "ab",
+			want:   []ProblemError{{In: "cookie", Name: "session", Message: "must be at least 3 characters long"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/items/"+tt.itemId+"?"+tt.query, nil)
+			if tt.xIds != "" {
+				req.Header.Set("X-Ids", tt.xIds)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: tt.cookie})
+			}
+			// The name of the path parameter of the route differs from the specification.
+			c := newRequestValidatorTestContext(req, "/items/:id", tt.itemId)
+			op := ops.find(c)
+			if op == nil {
+				t.Fatal("the operation is not found")
+			}
+			if got := validateParameters(c, ops, op); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateParametersSwagger(t *testing.T) {
+	ops := newTestSpecOperations(t, requestValidatorTestSwaggerSpec)
+	tests := []struct {
+		name  string
+		query string
+		want  []ProblemError
+	}{
+		{name: "valid", query: "limit=1&ids=1|2&tags=a&tags=b"},
+		{name: "missing required parameter", want: []ProblemError{{In: "query", Name: "limit", Message: "is required"}}},
+		{
+			name:  "collection formats",
+			query: "limit=11&ids=1|x&tags=a&tags=abcd",
+			want: []ProblemError{
+				{In: "query", Name: "ids", Pointer: "/1", Message: "must be integer"},
+				{In: "query", Name: "tags", Pointer: "/1", Message: "must be at most 3 characters long"},
+				{In: "query", Name: "limit", Message: "must be <= 10"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newRequestValidatorTestContext(httptest.NewRequest(http.MethodPost, "/v1/items?"+tt.query, nil), "/v1/items")
+			op := ops.find(c)
+			if op == nil {
+				t.Fatal("the operation is not found")
+			}
+			if got := validateParameters(c, ops, op); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRequestBody(t *testing.T) {
+	openAPI := newTestSpecOperations(t, requestValidatorTestSpec)
+	swagger := newTestSpecOperations(t, requestValidatorTestSwaggerSpec)
+	tests := []struct {
+		name        string
+		ops         *specOperations
+		contentType string
+		body        string
+		maxBodySize int64
+		want        []ProblemError
+		wantStatus  int
+	}{
+		{name: "valid", ops: openAPI, contentType: "application/json", body: `{"name": "a"}`},
+		{name: "content type with parameters", ops: openAPI, contentType: "application/json; charset=utf-8", body: `{"name": "a"}`},
+		{
+			name:        "invalid property",
+			ops:         openAPI,
+			contentType: "application/json",
+			body:        `{"name": 1}`,
+			want:        []ProblemError{{In: "body", Pointer: "/name", Message: "must be string"}},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{name: "missing required body", ops: openAPI, contentType: "application/json", want: []ProblemError{{In: "body", Message: "is required"}}, wantStatus: http.StatusBadRequest},
+		{
+			name:        "invalid JSON",
+			ops:         openAPI,
+			contentType: "application/json",
+			body:        `{"name":`,
+			want:        []ProblemError{{In: "body", Message: "is not valid JSON: unexpected EOF"}},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "data after the JSON value",
+			ops:         openAPI,
+			contentType: "application/json",
+			body:        `{"name": "a"} {}`,
+			want:        []ProblemError{{In: "body", Message: "is not valid JSON: unexpected data after the value"}},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "unsupported content type",
+			ops:         openAPI,
+			contentType: "application/xml",
+			body:        `<user/>`,
+			want:        []ProblemError{{In: "body", Message: `content type "application/xml" is not supported`}},
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{name: "wildcard content type without a schema", ops: openAPI, contentType: "text/plain", body: "hello"},
+		{
+			name:        "body larger than the max size",
+			ops:         openAPI,
+			contentType: "application/json",
+			body:        `{"name": "a"}`,
+			maxBodySize: 4,
+			want:        []ProblemError{{In: "body", Message: "must not be larger than 4 bytes"}},
+			wantStatus:  http.StatusRequestEntityTooLarge,
+		},
+		{name: "Swagger body parameter", ops: swagger, contentType: "application/json", body: `{"name": "a"}`},
+		{
+			name:        "invalid Swagger body parameter",
+			ops:         swagger,
+			contentType: "application/json",
+			body:        `{}`,
+			want:        []ProblemError{{In: "body", Message: `must have the property "name"`}},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "content type not in Swagger consumes",
+			ops:         swagger,
+			contentType: "text/plain",
+			body:        "hello",
+			want:        []ProblemError{{In: "body", Message: `content type "text/plain" is not supported`}},
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, target, routePath := http.MethodPost, "/users", "/users"
+			if tt.ops == swagger {
+				target, routePath = "/v1/items?limit=1", "/v1/items"
+			}
+			req := httptest.NewRequest(method, target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, tt.contentType)
+			c := newRequestValidatorTestContext(req, routePath)
+			op := tt.ops.find(c)
+			if op == nil || op.requestBody == nil {
+				t.Fatal("the operation with a request body is not found")
+			}
+			maxBodySize := tt.maxBodySize
+			if maxBodySize == 0 {
+				maxBodySize = DefaultRequestValidatorConfig.MaxBodySize
+			}
+
+			got, status, err := validateRequestBody(c, tt.ops, op.requestBody, maxBodySize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) || status != tt.wantStatus {
+				t.Errorf("validateRequestBody() = %+v, %d, want %+v, %d", got, status, tt.want, tt.wantStatus)
+			}
+			if status == http.StatusRequestEntityTooLarge {
+				return
+			}
+			// The body is restored for the handler.
+			if body, err := io.ReadAll(req.Body); err != nil || string(body) != tt.body {
+				t.Errorf("body = %q, %v, want %q", body, err, tt.body)
+			}
+		})
+	}
+}
+
+func TestRequestValidator(t *testing.T) {
+	m, err := NewRequestValidator(RequestValidatorConfig{Spec: requestValidatorTestSpec, MaxBodySize: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	e.Use(m)
+	e.POST("/users", func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.Blob(http.StatusCreated, echo.MIMEApplicationJSON, body)
+	})
+	e.POST("/undocumented", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantErrors  int
+	}{
+		{name: "valid", target: "/users", contentType: "application/json", body: `{"name": "a"}`, wantStatus: http.StatusCreated},
+		{name: "invalid", target: "/users", contentType: "application/json", body: `{"name": 1}`, wantStatus: http.StatusBadRequest, wantErrors: 1},
+		{name: "unsupported content type", target: "/users", contentType: "application/xml", body: `<user/>`, wantStatus: http.StatusUnsupportedMediaType, wantErrors: 1},
+		{
+			name:        "too large",
+			target:      "/users",
+			contentType: "application/json",
+			body:        `{"name": "` + strings.Repeat("a", 32) + `"}`,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantErrors:  1,
+		},
+		{name: "undocumented route", target: "/undocumented", contentType: "application/xml", body: `<user/>`, wantStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, tt.contentType)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantErrors == 0 {
+				if rec.Code == http.StatusCreated && rec.Body.String() != tt.body {
+					t.Errorf("body = %q, want %q", rec.Body, tt.body)
+				}
+				return
+			}
+			if got := rec.Header().Get(echo.HeaderContentType); got != mimeApplicationProblemJSON {
+				t.Errorf("Content-Type = %q, want %q", got, mimeApplicationProblemJSON)
+			}
+			var problem Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Status != tt.wantStatus || len(problem.Errors) != tt.wantErrors || problem.Instance != tt.target {
+				t.Errorf("problem = %+v, want the status %d with %d errors", problem, tt.wantStatus, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestNewRequestValidatorConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RequestValidatorConfig
+	}{
+		{name: "negative max body size", config: RequestValidatorConfig{Spec: requestValidatorTestSpec, MaxBodySize: -1}},
+		{name: "no spec", config: RequestValidatorConfig{}},
+		{name: "syntax error in the spec", config: RequestValidatorConfig{Spec: "openapi: [\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRequestValidator(tt.config); err == nil {
+				t.Error("err = nil, want an error")
+			}
+		})
+	}
+}
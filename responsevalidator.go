@@ -0,0 +1,290 @@
+package openapidocs
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResponseValidatorConfig is the configuration for the response validation middleware.
+type ResponseValidatorConfig struct {
+	// Skipper reports whether the validation is skipped for the request.
+	Skipper func(c echo.Context) bool
+	// Spec is the OpenAPI specification that the responses are validated against. It must be in JSON or YAML.
+	Spec string
+	// SpecSource is the source of the specification, which is used instead of Spec.
+	SpecSource SpecSource
+	// WatchSpec reloads the specification from SpecSource when it changes.
+	WatchSpec bool
+	// SharedSpec is a specification shared with the documentation sites and the other validation middleware,
+	// so that it is loaded and reloaded once. If it is not nil, Spec, SpecSource and WatchSpec are ignored.
+	SharedSpec *SharedSpec
+	// Mode is what the middleware does with a response that doesn't match the specification.
+	Mode ResponseValidatorMode
+	// Sampling makes the middleware validate only the fraction of the responses given by SampleRate.
+	// By default, all the responses are validated.
+	Sampling bool
+	// SampleRate is the fraction of the requests whose responses are validated if Sampling is true, from 0 to 1.
+	// 0 validates no responses, and 1 validates all the responses. It must be 0 or 1 if Sampling is false.
+	SampleRate float64
+	// ErrorHandler handles the problem of a response that doesn't match the specification in ResponseValidatorModeFail.
+	// By default, the problem is written as the response in `application/problem+json` instead of the invalid one.
+	ErrorHandler func(c echo.Context, problem *Problem) error
+}
+
+// ResponseValidatorMode is what the response validation middleware does with a response that doesn't match the specification.
+type ResponseValidatorMode string
+
+const (
+	// ResponseValidatorModeLog logs the differences as errors with the logger of echo.Echo, and sends the response as it is.
+	ResponseValidatorModeLog ResponseValidatorMode = "log"
+	// ResponseValidatorModeFail holds the response until it is validated, and replaces it with a problem
+	// of `500 Internal Server Error` if it doesn't match.
+	ResponseValidatorModeFail ResponseValidatorMode = "fail"
+)
+
+var DefaultResponseValidatorConfig = ResponseValidatorConfig{
+	Skipper:      nil,
+	Spec:         "",
+	SpecSource:   nil,
+	WatchSpec:    false,
+	SharedSpec:   nil,
+	Mode:         ResponseValidatorModeLog,
+	Sampling:     false,
+	SampleRate:   1,
+	ErrorHandler: writeProblem,
+}
+
+// responseProblemDetail is the detail of the problems of the responses that don't match the specification.
+const responseProblemDetail = "The response doesn't match the API specification."
+
+// maxCapturedResponseBody is the size of the response body that is captured for the validation.
+// The body of a larger response, or of a response that is flushed by the handler, is not validated.
+const maxCapturedResponseBody = 10 << 20
+
+// NewResponseValidator returns a middleware that validates the responses of the handlers against the responses
+// of the operations of the specification.
+//
+// The status code must be declared by the operation, as itself, its range such as `2XX`, or `default`.
+// The content type must be declared by the response, and a JSON body is validated against its schema.
+// The operation of a request is found in the same way as NewRequestValidator.
+// An error returned by the handler is returned as it is, and the response written for it by the error handler
+// of echo.Echo is not validated. In ResponseValidatorModeFail, a replaced response has the headers that
+// the response had before the handler was called.
+//
+// Register it after the middlewares that compress or otherwise encode the responses, so that it sees
+// the responses of the handlers as they are.
+func NewResponseValidator(config ResponseValidatorConfig) (echo.MiddlewareFunc, error) {
+	if config.Mode == "" {
+		config.Mode = DefaultResponseValidatorConfig.Mode
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultResponseValidatorConfig.ErrorHandler
+	}
+	if config.Mode != ResponseValidatorModeLog && config.Mode != ResponseValidatorModeFail {
+		return nil, fmt.Errorf("unknown response validator mode %q", config.Mode)
+	}
+	if config.Sampling && (config.SampleRate < 0 || config.SampleRate > 1) {
+		return nil, fmt.Errorf("the sample rate must be from 0 to 1: %v", config.SampleRate)
+	}
+	if !config.Sampling && config.SampleRate != 0 && config.SampleRate != DefaultResponseValidatorConfig.SampleRate {
+		return nil, fmt.Errorf("the sample rate %v is set, but sampling is not enabled: set Sampling to true", config.SampleRate)
+	}
+
+	spec, err := validatedSpecOf(config.SharedSpec, config.Spec, config.SpecSource, config.WatchSpec)
+	if err != nil {
+		return nil, err
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+			if config.Sampling && rand.Float64() >= config.SampleRate {
+				return next(c)
+			}
+			ops := spec.operations(c)
+			op := ops.find(c)
+			if op == nil || op.responses == nil {
+				return next(c)
+			}
+
+			res := c.Response()
+			// The headers are restored if the response is replaced, so that the problem doesn't have
+			// the headers of the invalid response.
+			header := res.Header().Clone()
+			w := &responseCapture{
+				ResponseWriter: res.Writer,
+				buffered:       config.Mode == ResponseValidatorModeFail,
+			}
+			res.Writer = w
+			err := next(c)
+			res.Writer = w.ResponseWriter
+			if err != nil {
+				// Send what the handler has written, if any. The error is the one to report even if it fails.
+				_ = w.flush()
+				return err
+			}
+			if !res.Committed {
+				return nil
+			}
+
+			errs := validateResponse(c, ops, op, res.Status, w)
+			if len(errs) == 0 || !w.buffered {
+				if len(errs) > 0 {
+					logResponseErrors(c, errs)
+				}
+				return w.flush()
+			}
+			// Discard the invalid response, which has not been sent yet.
+			res.Committed = false
+			res.Size = 0
+			h := res.Header()
+			clear(h)
+			maps.Copy(h, header)
+			return config.ErrorHandler(c, newProblem(c, http.StatusInternalServerError, responseProblemDetail, errs))
+		}
+	}, nil
+}
+
+// ResponseValidator is like NewResponseValidator, but panics if an error occurs.
+func ResponseValidator(config ResponseValidatorConfig) echo.MiddlewareFunc {
+	m, err := NewResponseValidator(config)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// validateResponse validates the captured response against the responses of op, and returns the differences.
+func validateResponse(c echo.Context, ops *specOperations, op *specOperation, status int, w *responseCapture) []ProblemError {
+	response, ok := findResponse(op.responses, status)
+	if !ok {
+		return []ProblemError{{In: "status", Message: fmt.Sprintf("%d is not declared", status)}}
+	}
+	if w.truncated || c.Request().Method == http.MethodHead || status == http.StatusNoContent || status == http.StatusNotModified {
+		return nil
+	}
+
+	content, _ := response["content"].(map[string]any)
+	body := w.body.Bytes()
+	if len(content) == 0 {
+		if len(body) > 0 {
+			return []ProblemError{{In: "body", Message: "must be empty"}}
+		}
+		return nil
+	}
+	if len(body) == 0 {
+		return []ProblemError{{In: "body", Message: "must not be empty"}}
+	}
+
+	contentType := c.Response().Header().Get(echo.HeaderContentType)
+	mediaType, _, ok := findMediaType(content, contentType)
+	if !ok {
+		return []ProblemError{{In: "header", Name: echo.HeaderContentType, Message: fmt.Sprintf("%q is not declared", contentType)}}
+	}
+	schema, ok := mediaType["schema"]
+	if !ok || !isJSONMediaType(contentType) {
+		return nil
+	}
+	value, err := decodeJSONValue(body)
+	if err != nil {
+		return []ProblemError{{In: "body", Message: "is not valid JSON: " + err.Error()}}
+	}
+	var errs []ProblemError
+	for _, v := range ops.validator.validate(schema, value, "", schemaDirectionResponse) {
+		errs = append(errs, ProblemError{In: "body", Pointer: v.pointer, Message: v.message})
+	}
+	return errs
+}
+
+// findResponse returns the response object of the status code, which is the one of the status code itself,
+// its range such as `2XX`, or `default`.
+func findResponse(responses map[string]any, status int) (map[string]any, bool) {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response, ok := responses[key].(map[string]any); ok {
+			return response, true
+		}
+	}
+	return nil, false
+}
+
+// logResponseErrors logs the differences between the response and the specification.
+func logResponseErrors(c echo.Context, errs []ProblemError) {
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, e.String())
+	}
+	c.Logger().Errorf("openapidocs: the response of %s %s doesn't match the specification: %s",
+		c.Request().Method, c.Path(), strings.Join(messages, "; "))
+}
+
+// responseCapture is an http.ResponseWriter that captures the response for the validation.
+// If buffered is true, the response is held until flush is called, so that it can be replaced.
+type responseCapture struct {
+	http.ResponseWriter
+	buffered bool
+	status   int
+	body     bytes.Buffer
+	// truncated reports whether the body is not captured entirely, because it is too large or flushed by the handler.
+	truncated bool
+}
+
+func (w *responseCapture) WriteHeader(code int) {
+	w.status = code
+	if !w.buffered {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *responseCapture) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.truncated && w.body.Len()+len(b) > maxCapturedResponseBody {
+		w.truncated = true
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if !w.truncated {
+		w.body.Write(b)
+	}
+	if w.buffered {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends the response held so far, and the rest of it is not held, because the handler streams it.
+func (w *responseCapture) Flush() {
+	w.truncated = true
+	if err := w.flush(); err != nil {
+		return
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *responseCapture) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// flush sends the held response, and stops holding the response.
+func (w *responseCapture) flush() error {
+	if !w.buffered {
+		return nil
+	}
+	w.buffered = false
+	if w.status == 0 {
+		return nil
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.body.Bytes())
+	return err
+}
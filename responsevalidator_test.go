@@ -0,0 +1,221 @@
+package openapidocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const responseValidatorTestSpec = `openapi: 3.0.0
+info:
+  title: Test
+  version: "1"
+paths:
+  /users/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: The user.
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name:
+                    type: string
+        "404":
+          description: The user is not found.
+`
+
+var errResponseValidatorTestNotFound = echo.NewHTTPError(http.StatusNotFound, "user not found")
+
+// newResponseValidatorTestEcho returns an echo.Echo with the response validator configured with config,
+// and the buffer of its logs. The user 1 is valid, the user 2 is invalid, and the user 3 is an error.
+func newResponseValidatorTestEcho(t *testing.T, config ResponseValidatorConfig) (*echo.Echo, *bytes.Buffer) {
+	t.Helper()
+	config.Spec = responseValidatorTestSpec
+	m, err := NewResponseValidator(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	logs := new(bytes.Buffer)
+	e.Logger.SetOutput(logs)
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set("X-Request-Id", "abc")
+			return next(c)
+		}
+	})
+	e.Use(m)
+	e.GET("/users/:id", func(c echo.Context) error {
+		switch c.Param("id") {
+		case "1":
+			return c.JSON(http.StatusOK, map[string]any{"name": "Alice"})
+		case "2":
+			c.Response().Header().Set("X-Handler", "yes")
+			return c.JSON(http.StatusOK, map[string]any{"name": 2})
+		default:
+			return errResponseValidatorTestNotFound
+		}
+	})
+	return e, logs
+}
+
+func serveResponseValidatorTest(e *echo.Echo, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestResponseValidatorLogMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantBody   string
+		wantLog    string
+	}{
+		{name: "valid", target: "/users/1", wantStatus: http.StatusOK, wantBody: `{"name":"Alice"}`},
+		{name: "invalid", target: "/users/2", wantStatus: http.StatusOK, wantBody: `{"name":2}`, wantLog: "/name: must be string"},
+		{name: "handler error", target: "/users/3", wantStatus: http.StatusNotFound, wantBody: `{"message":"user not found"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, logs := newResponseValidatorTestEcho(t, ResponseValidatorConfig{Mode: ResponseValidatorModeLog})
+			rec := serveResponseValidatorTest(e, tt.target)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.wantBody {
+				t.Errorf("body = %s, want %s", got, tt.wantBody)
+			}
+			if tt.wantLog == "" {
+				if logs.Len() != 0 {
+					t.Errorf("logs = %s, want none", logs)
+				}
+			} else if !strings.Contains(logs.String(), tt.wantLog) {
+				t.Errorf("logs = %s, want %q", logs, tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestResponseValidatorFailMode(t *testing.T) {
+	e, logs := newResponseValidatorTestEcho(t, ResponseValidatorConfig{Mode: ResponseValidatorModeFail})
+
+	rec := serveResponseValidatorTest(e, "/users/1")
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"name":"Alice"}` {
+		t.Errorf("valid response = %d %s, want it as it is", rec.Code, rec.Body)
+	}
+
+	rec = serveResponseValidatorTest(e, "/users/2")
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if got := rec.Header().Get(echo.HeaderContentType); got != mimeApplicationProblemJSON {
+		t.Errorf("Content-Type = %q, want %q", got, mimeApplicationProblemJSON)
+	}
+	if got := rec.Header().Get("X-Handler"); got != "" {
+		t.Errorf("X-Handler = %q, want the header of the invalid response removed", got)
+	}
+	if got := rec.Header().Get("X-Request-Id"); got != "abc" {
+		t.Errorf("X-Request-Id = %q, want the header set before the handler kept", got)
+	}
+	var problem Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	want := ProblemError{In: "body", Pointer: "/name", Message: "must be string"}
+	if len(problem.Errors) != 1 || problem.Errors[0] != want {
+		t.Errorf("errors = %+v, want [%+v]", problem.Errors, want)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("logs = %s, want none", logs)
+	}
+}
+
+func TestResponseValidatorReturnsHandlerError(t *testing.T) {
+	for _, mode := range []ResponseValidatorMode{ResponseValidatorModeLog, ResponseValidatorModeFail} {
+		t.Run(string(mode), func(t *testing.T) {
+			e, _ := newResponseValidatorTestEcho(t, ResponseValidatorConfig{Mode: mode})
+			var handled []error
+			e.HTTPErrorHandler = func(err error, c echo.Context) {
+				handled = append(handled, err)
+				e.DefaultHTTPErrorHandler(err, c)
+			}
+
+			rec := serveResponseValidatorTest(e, "/users/3")
+			if len(handled) != 1 || !errors.Is(handled[0], errResponseValidatorTestNotFound) {
+				t.Errorf("handled errors = %v, want the error of the handler once", handled)
+			}
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestResponseValidatorSampling(t *testing.T) {
+	tests := []struct {
+		name       string
+		sampling   bool
+		sampleRate float64
+		wantStatus int
+	}{
+		{name: "sampling disabled validates all", sampling: false, sampleRate: 0, wantStatus: http.StatusInternalServerError},
+		{name: "sampling disabled with the default sample rate validates all", sampling: false, sampleRate: 1, wantStatus: http.StatusInternalServerError},
+		{name: "sample rate 0 validates none", sampling: true, sampleRate: 0, wantStatus: http.StatusOK},
+		{name: "sample rate 1 validates all", sampling: true, sampleRate: 1, wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := newResponseValidatorTestEcho(t, ResponseValidatorConfig{
+				Mode:       ResponseValidatorModeFail,
+				Sampling:   tt.sampling,
+				SampleRate: tt.sampleRate,
+			})
+			for i := 0; i < 20; i++ {
+				if rec := serveResponseValidatorTest(e, "/users/2"); rec.Code != tt.wantStatus {
+					t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestNewResponseValidatorConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ResponseValidatorConfig
+	}{
+		{name: "unknown mode", config: ResponseValidatorConfig{Mode: "panic"}},
+		{name: "negative sample rate", config: ResponseValidatorConfig{Sampling: true, SampleRate: -0.1}},
+		{name: "sample rate over 1", config: ResponseValidatorConfig{Sampling: true, SampleRate: 1.5}},
+		{name: "sample rate without sampling", config: ResponseValidatorConfig{SampleRate: 0.1}},
+		{name: "no spec", config: ResponseValidatorConfig{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.name != "no spec" {
+				tt.config.Spec = responseValidatorTestSpec
+			}
+			if _, err := NewResponseValidator(tt.config); err == nil {
+				t.Error("err = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package openapidocs
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// Router is an echo router to register documentation handlers on.
+// Both *echo.Echo and *echo.Group implement it, so the documentation can be mounted under a group
+// and run behind the group's middleware.
+type Router interface {
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+var (
+	_ Router = (*echo.Echo)(nil)
+	_ Router = (*echo.Group)(nil)
+)
@@ -0,0 +1,384 @@
+package openapidocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RouteSpecConfig is the configuration for GenerateRouteSpec to generate an OpenAPI specification from the routes of echo.
+type RouteSpecConfig struct {
+	// Title is the `info.title` of the specification.
+	Title string
+	// Version is the `info.version` of the specification.
+	Version string
+	// Description is the `info.description` of the specification.
+	Description string
+	// Servers is the URLs of the servers of the API, such as `https://api.example.com`.
+	Servers []string
+	// TagPrefix is the path prefix that is ignored when the operations are grouped into tags, such as `/api/v1`.
+	// An operation is tagged with the first segment of its path after TagPrefix, such as `users` for `/api/v1/users/:id`.
+	TagPrefix string
+	// Skip reports whether a route is left out of the specification.
+	// The routes of the documentation sites and the index pages registered by this package are always left out.
+	Skip func(route *echo.Route) bool
+}
+
+var DefaultRouteSpecConfig = RouteSpecConfig{
+	Title:       "API",
+	Version:     "0.0.0",
+	Description: "",
+	Servers:     nil,
+	TagPrefix:   "",
+	Skip:        nil,
+}
+
+// RouteDoc is the documentation of a route in the specification generated by GenerateRouteSpec.
+type RouteDoc struct {
+	// Summary is the summary of the operation.
+	Summary string
+	// Description is the description of the operation.
+	Description string
+	// OperationID is the ID of the operation.
+	// If it is empty, it is generated from the method and the path, such as `getUsersById` for `GET /users/:id`.
+	OperationID string
+	// Tags is the tags of the operation. If it is empty, the operation is tagged with the first segment of its path.
+	Tags []string
+	// Deprecated marks the operation as deprecated.
+	Deprecated bool
+	// Request is a value of the Go type of the JSON request body, such as `CreateUserRequest{}`, or its *Schema.
+	// The schema is generated by SchemaReflector. If it is nil, the operation has no request body.
+	Request any
+	// Response is a value of the Go type of the JSON response body, such as `User{}`, or its *Schema.
+	// The schema is generated by SchemaReflector. If it is nil, the response has no body.
+	Response any
+	// ResponseStatus is the status code of the response. If it is 0, 200 is used.
+	ResponseStatus int
+}
+
+// DescribeRoute sets the documentation of route in the specification generated by GenerateRouteSpec, and returns route.
+// It is meant to wrap the registration of the route:
+//
+//	openapidocs.DescribeRoute(e.GET("/users/:id", getUser), openapidocs.RouteDoc{
+//		Summary:  "Get a user",
+//		Response: User{},
+//	})
+func DescribeRoute(route *echo.Route, doc RouteDoc) *echo.Route {
+	updateRouteState(route, func(state *routeState) {
+		state.doc = &doc
+	})
+	return route
+}
+
+// describedRoutes returns the documentation of the routes of e set by DescribeRoute.
+func describedRoutes(e *echo.Echo) map[*echo.Route]RouteDoc {
+	docs := map[*echo.Route]RouteDoc{}
+	for _, route := range e.Routes() {
+		if state, ok := routeStateOf(route); ok && state.doc != nil {
+			docs[route] = *state.doc
+		}
+	}
+	return docs
+}
+
+// routeSpecOpenAPIVersion is the OpenAPI version of the specifications generated by GenerateRouteSpec.
+const routeSpecOpenAPIVersion = "3.1.0"
+
+// routeSpecWildcardParam is the name of the path parameter that a `*` of an echo route is converted into.
+const routeSpecWildcardParam = "path"
+
+// routeSpecMethods is the HTTP methods that OpenAPI can describe.
+var routeSpecMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodPut:     true,
+	http.MethodPost:    true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+	http.MethodHead:    true,
+	http.MethodPatch:   true,
+	http.MethodTrace:   true,
+}
+
+type routeSpecDocument struct {
+	OpenAPI    string                                    `json:"openapi"`
+	Info       routeSpecInfo                             `json:"info"`
+	Servers    []routeSpecServer                         `json:"servers,omitempty"`
+	Tags       []routeSpecTag                            `json:"tags,omitempty"`
+	Paths      map[string]map[string]*routeSpecOperation `json:"paths"`
+	Components *routeSpecComponents                      `json:"components,omitempty"`
+}
+
+type routeSpecInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type routeSpecServer struct {
+	Url string `json:"url"`
+}
+
+type routeSpecTag struct {
+	Name string `json:"name"`
+}
+
+type routeSpecOperation struct {
+	Tags        []string                      `json:"tags,omitempty"`
+	Summary     string                        `json:"summary,omitempty"`
+	Description string                        `json:"description,omitempty"`
+	OperationID string                        `json:"operationId"`
+	Parameters  []routeSpecParameter          `json:"parameters,omitempty"`
+	RequestBody *routeSpecRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*routeSpecResponse `json:"responses"`
+	Deprecated  bool                          `json:"deprecated,omitempty"`
+}
+
+type routeSpecParameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type routeSpecRequestBody struct {
+	Required bool                          `json:"required"`
+	Content  map[string]routeSpecMediaType `json:"content"`
+}
+
+type routeSpecResponse struct {
+	Description string                        `json:"description"`
+	Content     map[string]routeSpecMediaType `json:"content,omitempty"`
+}
+
+type routeSpecMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type routeSpecComponents struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// GenerateRouteSpec generates an OpenAPI 3.1 specification in JSON from the routes registered on e.
+// The result can be passed to Spec of the configurations of the documentation handlers.
+//
+// The path parameters of the routes such as `:id` are converted into `{id}`, and `*` is converted into `{path}`.
+// The operations are tagged with the first segment of their paths, and documented with RouteDoc set by DescribeRoute.
+// The routes of the documentation sites and the index pages registered by this package are left out,
+// so register the documentation after generating the specification, or generate it again when serving it.
+func GenerateRouteSpec(e *echo.Echo, config RouteSpecConfig) (string, error) {
+	if config.Title == "" {
+		config.Title = DefaultRouteSpecConfig.Title
+	}
+	if config.Version == "" {
+		config.Version = DefaultRouteSpecConfig.Version
+	}
+
+	g := &routeSpecGenerator{
+		reflector: NewSchemaReflector(),
+	}
+	doc := &routeSpecDocument{
+		OpenAPI: routeSpecOpenAPIVersion,
+		Info: routeSpecInfo{
+			Title:       config.Title,
+			Version:     config.Version,
+			Description: config.Description,
+		},
+		Paths: map[string]map[string]*routeSpecOperation{},
+	}
+	for _, u := range config.Servers {
+		doc.Servers = append(doc.Servers, routeSpecServer{Url: u})
+	}
+
+	// Sort the routes, because e.Routes returns them in random order and the operation IDs depend on the order.
+	routes := e.Routes()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	docs := describedRoutes(e)
+	operationIDs := map[string]bool{}
+	tags := map[string]bool{}
+	for _, route := range routes {
+		if !routeSpecMethods[route.Method] || isDocsRoute(route) || (config.Skip != nil && config.Skip(route)) {
+			continue
+		}
+		p, params := routeSpecPath(route.Path)
+		op := g.operation(route, docs[route], params, config.TagPrefix)
+
+		// Make the operation IDs unique, because different paths may generate the same ID.
+		id := op.OperationID
+		for i := 2; operationIDs[op.OperationID]; i++ {
+			op.OperationID = id + strconv.Itoa(i)
+		}
+		operationIDs[op.OperationID] = true
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+
+		if doc.Paths[p] == nil {
+			doc.Paths[p] = map[string]*routeSpecOperation{}
+		}
+		doc.Paths[p][strings.ToLower(route.Method)] = op
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, routeSpecTag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool {
+		return doc.Tags[i].Name < doc.Tags[j].Name
+	})
+	if schemas := g.reflector.Schemas(); len(schemas) > 0 {
+		doc.Components = &routeSpecComponents{Schemas: schemas}
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to generate the specification: %w", err)
+	}
+	return string(data), nil
+}
+
+// routeSpecGenerator generates the operations of a specification generated by GenerateRouteSpec.
+type routeSpecGenerator struct {
+	// reflector generates the schemas of the request and response bodies.
+	// The schemas of the named struct types are the `components/schemas` of the specification.
+	reflector *SchemaReflector
+}
+
+// operation returns the operation of route documented with doc.
+func (g *routeSpecGenerator) operation(route *echo.Route, doc RouteDoc, params []string, tagPrefix string) *routeSpecOperation {
+	op := &routeSpecOperation{
+		Tags:        doc.Tags,
+		Summary:     doc.Summary,
+		Description: doc.Description,
+		OperationID: doc.OperationID,
+		Responses:   map[string]*routeSpecResponse{},
+		Deprecated:  doc.Deprecated,
+	}
+	if len(op.Tags) == 0 {
+		if tag := routeSpecTagName(route.Path, tagPrefix); tag != "" {
+			op.Tags = []string{tag}
+		}
+	}
+	if op.OperationID == "" {
+		op.OperationID = routeSpecOperationID(route.Method, route.Path)
+	}
+
+	for _, name := range params {
+		op.Parameters = append(op.Parameters, routeSpecParameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: SchemaType{"string"}},
+		})
+	}
+
+	if doc.Request != nil {
+		op.RequestBody = &routeSpecRequestBody{
+			Required: true,
+			Content: map[string]routeSpecMediaType{
+				echo.MIMEApplicationJSON: {Schema: g.reflector.Reflect(doc.Request)},
+			},
+		}
+	}
+
+	status := doc.ResponseStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+	res := &routeSpecResponse{
+		Description: http.StatusText(status),
+	}
+	if res.Description == "" {
+		res.Description = "Response"
+	}
+	if doc.Response != nil {
+		res.Content = map[string]routeSpecMediaType{
+			echo.MIMEApplicationJSON: {Schema: g.reflector.Reflect(doc.Response)},
+		}
+	}
+	op.Responses[strconv.Itoa(status)] = res
+	return op
+}
+
+// routeSpecPath converts the path of an echo route into an OpenAPI path template,
+// and returns the names of the path parameters.
+func routeSpecPath(p string) (string, []string) {
+	var b strings.Builder
+	var params []string
+	for i := 0; i < len(p); i++ {
+		switch {
+		case p[i] == '\\' && i+1 < len(p) && p[i+1] == ':':
+			// An escaped colon is a literal colon.
+			b.WriteByte(':')
+			i++
+		case p[i] == ':':
+			end := strings.IndexByte(p[i:], '/')
+			if end < 0 {
+				end = len(p) - i
+			}
+			name := p[i+1 : i+end]
+			params = append(params, name)
+			b.WriteString("{" + name + "}")
+			i += end - 1
+		case p[i] == '*':
+			params = append(params, routeSpecWildcardParam)
+			b.WriteString("{" + routeSpecWildcardParam + "}")
+		default:
+			b.WriteByte(p[i])
+		}
+	}
+	return b.String(), params
+}
+
+// routeSpecTagName returns the tag of an operation for the path of an echo route,
+// which is the first segment of the path after prefix. It returns an empty string if the segment is a parameter.
+func routeSpecTagName(p, prefix string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if rest, ok := strings.CutPrefix(p, prefix); ok && (rest == "" || rest[0] == '/') {
+		p = rest
+	}
+	segment, _, _ := strings.Cut(strings.TrimPrefix(p, "/"), "/")
+	if segment == "" || strings.ContainsAny(segment, ":*") {
+		return ""
+	}
+	return segment
+}
+
+// routeSpecOperationID returns the operation ID generated from the method and the path of an echo route,
+// such as `getUsersById` for `GET /users/:id`.
+func routeSpecOperationID(method, p string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(p, "/") {
+		switch {
+		case segment == "":
+			continue
+		case segment == "*":
+			segment = "by " + routeSpecWildcardParam
+		case strings.HasPrefix(segment, ":"):
+			segment = "by " + segment[1:]
+		}
+		words := strings.FieldsFunc(segment, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, w := range words {
+			r, size := utf8.DecodeRuneInString(w)
+			b.WriteRune(unicode.ToUpper(r))
+			b.WriteString(w[size:])
+		}
+	}
+	if b.Len() == len(method) {
+		b.WriteString("Root")
+	}
+	return b.String()
+}
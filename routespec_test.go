@@ -0,0 +1,83 @@
+package openapidocs
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestGenerateRouteSpec(t *testing.T) {
+	h := func(c echo.Context) error { return nil }
+	e := echo.New()
+	g := e.Group("/api/v1")
+	DescribeRoute(g.GET("/users/:id", h), RouteDoc{Summary: "Get a user"})
+	e.POST("/users", h)
+	if err := RegisterRedocDocuments(e, "/docs", RedocConfig{Spec: documentsTestSpec}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The documentation of the routes of e is not used for the same routes of another echo.Echo.
+	other := echo.New()
+	other.GET("/api/v1/users/:id", h)
+
+	tests := []struct {
+		name        string
+		e           *echo.Echo
+		wantPaths   []string
+		wantSummary string
+	}{
+		{name: "described routes", e: e, wantPaths: []string{"/api/v1/users/{id}", "/users"}, wantSummary: "Get a user"},
+		{name: "other echo", e: other, wantPaths: []string{"/api/v1/users/{id}"}, wantSummary: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := GenerateRouteSpec(tt.e, RouteSpecConfig{TagPrefix: "/api/v1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc routeSpecDocument
+			if err := json.Unmarshal([]byte(spec), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if got := slices.Sorted(maps.Keys(doc.Paths)); !slices.Equal(got, tt.wantPaths) {
+				t.Errorf("paths = %v, want %v", got, tt.wantPaths)
+			}
+			op := doc.Paths["/api/v1/users/{id}"]["get"]
+			if op == nil {
+				t.Fatal("the operation of GET /api/v1/users/:id is not generated")
+			}
+			if op.Summary != tt.wantSummary {
+				t.Errorf("summary = %q, want %q", op.Summary, tt.wantSummary)
+			}
+			if op.OperationID != "getApiV1UsersById" || !slices.Equal(op.Tags, []string{"users"}) {
+				t.Errorf("operation ID and tags = %s, %v, want getApiV1UsersById, [users]", op.OperationID, op.Tags)
+			}
+			if len(op.Parameters) != 1 || op.Parameters[0].Name != "id" || op.Parameters[0].In != "path" {
+				t.Errorf("parameters = %+v, want the path parameter id", op.Parameters)
+			}
+		})
+	}
+}
+
+func TestRouteSpecPath(t *testing.T) {
+	tests := []struct {
+		path       string
+		want       string
+		wantParams []string
+	}{
+		{path: "/users", want: "/users"},
+		{path: "/users/:id/posts/:postId", want: "/users/{id}/posts/{postId}", wantParams: []string{"id", "postId"}},
+		{path: "/files/*", want: "/files/{path}", wantParams: []string{"path"}},
+		{path: `/time\:now`, want: "/time:now"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, params := routeSpecPath(tt.path)
+			if got != tt.want || !slices.Equal(params, tt.wantParams) {
+				t.Errorf("routeSpecPath() = %s, %v, want %s, %v", got, params, tt.want, tt.wantParams)
+			}
+		})
+	}
+}
@@ -1,13 +1,11 @@
 package openapidocs
 
 import (
-	"bytes"
 	"encoding/json"
 	"github.com/labstack/echo/v4"
 	htmltemplate "html/template"
-	"net/http"
-	"path"
-	"strings"
+	"io"
+	"io/fs"
 )
 
 // ScalarConfig is the configuration for ScalarDocumentsHandler to generate the OpenAPI documentation with Scalar.
@@ -18,10 +16,21 @@ type ScalarConfig struct {
 	Spec string
 	// SpecUrl is the URL of the OpenAPI specification. If Spec is not empty, SpecUrl is ignored.
 	SpecUrl string
+	// SiteConfig is the configuration of the documentation site that is common to all renderers,
+	// such as the source of the specification and HTTP caching.
+	SiteConfig
 	// Title is the title of the page.
 	Title string
 	// Template is a template string for rendering the page with html/template.
 	Template string
+	// UseEmbeddedAssets makes the page load the Scalar JavaScript file embedded in this package
+	// instead of loading it from a CDN. The file is served under the `_assets` path of the documentation site.
+	UseEmbeddedAssets bool
+	// AssetVersion is the version of Scalar to load. The default is the version of the embedded assets.
+	AssetVersion string
+	// ScriptIntegrity is the Subresource Integrity hash of the Scalar JavaScript file.
+	// If it is empty, the known hash of AssetVersion is used.
+	ScriptIntegrity string
 
 	// IsEditable is the Scalar `isEditable` configuration.
 	IsEditable bool
@@ -44,18 +53,23 @@ type ScalarConfig struct {
 type scalarTemplateParams struct {
 	ScalarConfig
 	BasePath                  string
+	Specs                     []PageSpec
+	Renderers                 []PageRenderer
+	LiveReloadUrl             string
 	ApiReferenceConfiguration htmltemplate.JS
+	ScriptUrl                 string
 }
 
 type apiReferenceConfiguration struct {
-	IsEditable   bool                          `json:"isEditable,omitempty"`
-	Spec         apiReferenceConfigurationSpec `json:"spec"`
-	ProxyUrl     string                        `json:"proxyUrl,omitempty"`
-	DarkMode     bool                          `json:"darkMode,omitempty"`
-	Layout       ScalarLayout                  `json:"layout,omitempty"`
-	Theme        ScalarTheme                   `json:"theme,omitempty"`
-	ShowSidebar  bool                          `json:"showSidebar"` // the default value is true, that is the reason why it is not omitted
-	SearchHotKey string                        `json:"searchHotKey,omitempty"`
+	IsEditable   bool                              `json:"isEditable,omitempty"`
+	Spec         apiReferenceConfigurationSpec     `json:"spec"`
+	Sources      []apiReferenceConfigurationSource `json:"sources,omitempty"`
+	ProxyUrl     string                            `json:"proxyUrl,omitempty"`
+	DarkMode     bool                              `json:"darkMode,omitempty"`
+	Layout       ScalarLayout                      `json:"layout,omitempty"`
+	Theme        ScalarTheme                       `json:"theme,omitempty"`
+	ShowSidebar  bool                              `json:"showSidebar"` // the default value is true, that is the reason why it is not omitted
+	SearchHotKey string                            `json:"searchHotKey,omitempty"`
 }
 
 type ScalarLayout string
@@ -86,20 +100,34 @@ type apiReferenceConfigurationSpec struct {
 	URL string `json:"url"`
 }
 
+// apiReferenceConfigurationSource is a document of the Scalar `sources` configuration, which shows several documents.
+type apiReferenceConfigurationSource struct {
+	Title   string `json:"title"`
+	Slug    string `json:"slug"`
+	URL     string `json:"url"`
+	Default bool   `json:"default,omitempty"`
+}
+
 var DefaultScalarConfig = ScalarConfig{
-	Spec:         "",
-	SpecUrl:      "",
-	Title:        "API documentation with Scalar",
-	Template:     defaultScalarTemplate,
-	IsEditable:   false,
-	ProxyUrl:     "",
-	DarkMode:     false,
-	Layout:       ScalarLayoutModern,
-	Theme:        ScalarThemeDefault,
-	HideSidebar:  false,
-	SearchHotKey: "",
+	Spec:              "",
+	SpecUrl:           "",
+	SiteConfig:        DefaultSiteConfig,
+	Title:             "API documentation with Scalar",
+	Template:          defaultScalarTemplate,
+	UseEmbeddedAssets: false,
+	AssetVersion:      scalarAssets.version,
+	ScriptIntegrity:   "",
+	IsEditable:        false,
+	ProxyUrl:          "",
+	DarkMode:          false,
+	Layout:            ScalarLayoutModern,
+	Theme:             ScalarThemeDefault,
+	HideSidebar:       false,
+	SearchHotKey:      "",
 }
 
+const scalarScriptFile = "standalone.js"
+
 const defaultScalarTemplate = `<html lang="en">
 <head>
   <meta charset="utf-8">
@@ -107,90 +135,141 @@ const defaultScalarTemplate = `<html lang="en">
   <title>{{ .Title }}</title>
 </head>
 <body>
+  {{- template "renderer-switcher" .Renderers }}
   <script id="api-reference" type="application/json"></script>
   <script>
     var configuration = {{ .ApiReferenceConfiguration }};
     var apiReference = document.getElementById('api-reference');
     apiReference.dataset.configuration = JSON.stringify(configuration);
   </script>
-  <script src="https://cdn.jsdelivr.net/npm/@scalar/api-reference"></script>
+  <script src="{{ .ScriptUrl }}"{{ if .ScriptIntegrity }} integrity="{{ .ScriptIntegrity }}"{{ end }} crossorigin="anonymous"></script>
+  {{- template "live-reload" .LiveReloadUrl }}
 </body>
 </html>
 `
 
-// ScalarDocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with Scalar.
-func ScalarDocumentsHandler(config ScalarConfig) echo.HandlerFunc {
+// scalarRenderer renders the page of Scalar.
+type scalarRenderer struct {
+	config ScalarConfig
+	tmpl   *htmltemplate.Template
+	assets fs.FS
+}
+
+// NewScalarRenderer returns a Renderer of the OpenAPI documentation with Scalar.
+// The specification and the HTTP settings in config, such as Spec and CacheControl, are not used by the renderer.
+// Pass them to Documents in a DocumentsConfig.
+// It returns an error if the configuration is invalid.
+func NewScalarRenderer(config ScalarConfig) (Renderer, error) {
 	if config.Template == "" {
 		config.Template = DefaultScalarConfig.Template
 	}
 	if config.Title == "" {
 		config.Title = DefaultScalarConfig.Title
 	}
+	if config.AssetVersion == "" {
+		config.AssetVersion = DefaultScalarConfig.AssetVersion
+	}
+	var err error
+	if config.ScriptIntegrity, err = scalarAssets.integrityOf(config.AssetVersion, scalarScriptFile, config.ScriptIntegrity, "ScriptIntegrity"); err != nil {
+		return nil, err
+	}
 
-	useSpecUrl := false
-	if config.Spec == "" {
-		if config.SpecUrl == "" {
-			panic("either Spec or SpecUrl must be set")
+	var assets fs.FS
+	if config.UseEmbeddedAssets {
+		if assets, err = scalarAssets.embeddedFS(config.AssetVersion, scalarScriptFile); err != nil {
+			return nil, err
 		}
-		useSpecUrl = true
 	}
 
-	pageTmpl := htmltemplate.Must(htmltemplate.New("T").Parse(config.Template))
-	return func(c echo.Context) error {
-		p := c.Request().URL.Path
-
-		// determine the base path
-		relPath := c.Param("*")
-		basePath := strings.TrimSuffix(p, relPath)
-
-		var specUrl string
-		if !useSpecUrl {
-			specUrl = path.Join(basePath, "openapi-spec")
-			if strings.HasSuffix(p, specUrl) {
-				return c.Blob(http.StatusOK, "text/plain; charset=utf-8", []byte(config.Spec))
-			}
-		} else {
-			specUrl = config.SpecUrl
-		}
+	tmpl, err := parsePageTemplate(config.Template)
+	if err != nil {
+		return nil, err
+	}
 
-		if relPath != "" {
-			// The document site only works with the base path.
-			return c.Redirect(http.StatusFound, basePath)
-		}
+	return &scalarRenderer{
+		config: config,
+		tmpl:   tmpl,
+		assets: assets,
+	}, nil
+}
 
-		apiReferenceConfiguration := apiReferenceConfiguration{
-			IsEditable: config.IsEditable,
-			Spec: apiReferenceConfigurationSpec{
-				URL: specUrl,
-			},
-			ProxyUrl:     config.ProxyUrl,
-			DarkMode:     config.DarkMode,
-			Layout:       config.Layout,
-			Theme:        config.Theme,
-			ShowSidebar:  !config.HideSidebar,
-			SearchHotKey: config.SearchHotKey,
-		}
+func (r *scalarRenderer) Render(w io.Writer, page Page) error {
+	apiReferenceConfiguration := apiReferenceConfiguration{
+		IsEditable: r.config.IsEditable,
+		Spec: apiReferenceConfigurationSpec{
+			URL: page.SpecUrl,
+		},
+		ProxyUrl:     r.config.ProxyUrl,
+		DarkMode:     r.config.DarkMode,
+		Layout:       r.config.Layout,
+		Theme:        r.config.Theme,
+		ShowSidebar:  !r.config.HideSidebar,
+		SearchHotKey: r.config.SearchHotKey,
+	}
+	for _, spec := range page.Specs {
+		apiReferenceConfiguration.Sources = append(apiReferenceConfiguration.Sources, apiReferenceConfigurationSource{
+			Title:   spec.Title,
+			Slug:    spec.Name,
+			URL:     spec.Url,
+			Default: spec.Selected,
+		})
+	}
 
-		jsonDate, err := json.Marshal(apiReferenceConfiguration)
-		if err != nil {
-			return err
-		}
+	jsonDate, err := json.Marshal(apiReferenceConfiguration)
+	if err != nil {
+		return err
+	}
 
-		params := scalarTemplateParams{
-			ScalarConfig:              config,
-			BasePath:                  basePath,
-			ApiReferenceConfiguration: htmltemplate.JS(jsonDate),
-		}
+	return r.tmpl.Execute(w, scalarTemplateParams{
+		ScalarConfig:              r.config,
+		BasePath:                  page.BasePath,
+		Specs:                     page.Specs,
+		Renderers:                 page.Renderers,
+		LiveReloadUrl:             page.LiveReloadUrl,
+		ApiReferenceConfiguration: htmltemplate.JS(jsonDate),
+		ScriptUrl:                 scalarAssets.url(r.assets, page.BasePath, r.config.AssetVersion, scalarScriptFile),
+	})
+}
 
-		buf := new(bytes.Buffer)
-		if err := pageTmpl.Execute(buf, params); err != nil {
-			panic(err)
-		}
-		return c.HTML(http.StatusOK, buf.String())
+func (r *scalarRenderer) Assets() fs.FS {
+	return r.assets
+}
+
+// NewScalarHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with Scalar.
+// It returns an error if the configuration is invalid.
+func NewScalarHandler(config ScalarConfig) (echo.HandlerFunc, error) {
+	renderer, err := NewScalarRenderer(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewDocumentsHandler(DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer)
+}
+
+// ScalarDocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with Scalar.
+// It panics if the configuration is invalid. Use NewScalarHandler to handle the error.
+func ScalarDocumentsHandler(config ScalarConfig) echo.HandlerFunc {
+	h, err := NewScalarHandler(config)
+	if err != nil {
+		panic(err)
 	}
+	return h
 }
 
 // ScalarDocuments registers a handler to serve the OpenAPI documentation with Scalar.
+// It panics if the configuration is invalid. Use RegisterScalarDocuments to handle the error.
 func ScalarDocuments(e *echo.Echo, pathPrefix string, config ScalarConfig) {
-	e.GET(pathPrefix+"*", ScalarDocumentsHandler(config))
+	if err := RegisterScalarDocuments(e, pathPrefix, config); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterScalarDocuments registers a handler to serve the OpenAPI documentation with Scalar on r,
+// which is either *echo.Echo or *echo.Group. The middleware is applied to the handler in addition to the middleware of r.
+// It returns an error if the configuration is invalid.
+func RegisterScalarDocuments(r Router, pathPrefix string, config ScalarConfig, middleware ...echo.MiddlewareFunc) error {
+	renderer, err := NewScalarRenderer(config)
+	if err != nil {
+		return err
+	}
+	return registerDocuments(r, pathPrefix, DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer, openAPISpecKind, "Scalar", config.Title, middleware)
 }
@@ -0,0 +1,531 @@
+package openapidocs
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is an OpenAPI 3.1 schema object, which is a JSON Schema.
+// SchemaReflector generates it from Go types, and it is encoded with encoding/json.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 SchemaType         `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Examples             []any              `json:"examples,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// SchemaType is the `type` of a Schema. A single type is encoded as a string, and several types as an array,
+// such as `["string", "null"]` for a nullable string.
+type SchemaType []string
+
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = SchemaType{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// Enum is implemented by a Go type whose values are limited, such as a string type with constants.
+// SchemaReflector sets the values to the `enum` of the schema of the type.
+type Enum interface {
+	// EnumValues returns the values of the type.
+	EnumValues() []any
+}
+
+// schemaRefPrefix is the prefix of the references to the schemas of the named struct types.
+const schemaRefPrefix = "#/components/schemas/"
+
+var (
+	enumType          = reflect.TypeFor[Enum]()
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonNumberType    = reflect.TypeFor[json.Number]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// SchemaReflector generates the schemas of Go types as encoding/json encodes them.
+//
+// It respects the `json` struct tags including `omitempty`, promotes the fields of embedded structs with the same
+// precedence rules as encoding/json, and generates `date-time` strings for time.Time and the `enum` of the types
+// that implement Enum.
+// A field is required unless it has `omitempty` or `omitzero`, and a pointer field without them is nullable.
+// The `validate` struct tags of github.com/go-playground/validator, such as `required`, `min`, `max` and `oneof`,
+// add the constraints to the schemas, and the `example` struct tags add the examples.
+//
+// The schemas of named struct types are collected in Schemas, which is the `components/schemas` of a specification,
+// and referred to with `$ref`.
+type SchemaReflector struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// NewSchemaReflector returns a new SchemaReflector.
+func NewSchemaReflector() *SchemaReflector {
+	return &SchemaReflector{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// Reflect returns the schema of the Go type of v, such as `User{}`. v may also be a reflect.Type.
+// If v is a *Schema, it is returned as it is.
+func (r *SchemaReflector) Reflect(v any) *Schema {
+	switch v := v.(type) {
+	case nil:
+		return &Schema{}
+	case *Schema:
+		return v
+	case reflect.Type:
+		return r.reflect(v)
+	default:
+		return r.reflect(reflect.TypeOf(v))
+	}
+}
+
+// Schemas returns the schemas of the named struct types referred to by the schemas returned by Reflect, by their names.
+func (r *SchemaReflector) Schemas() map[string]*Schema {
+	return r.schemas
+}
+
+func (r *SchemaReflector) reflect(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Implements(enumType) || reflect.PointerTo(t).Implements(enumType) {
+		s := r.reflectKind(t)
+		// The values are copied, because the schema may append to them.
+		s.Enum = slices.Clone(reflect.New(t).Interface().(Enum).EnumValues())
+		return s
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: SchemaType{"string"}, Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t == jsonNumberType:
+		return &Schema{Type: SchemaType{"number"}}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// The encoding of the type is unknown.
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: SchemaType{"string"}}
+	}
+	return r.reflectKind(t)
+}
+
+// reflectKind returns the schema of t by its kind.
+func (r *SchemaReflector) reflectKind(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16:
+		return &Schema{Type: SchemaType{"integer"}}
+	case reflect.Int32:
+		return &Schema{Type: SchemaType{"integer"}, Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: SchemaType{"integer"}, Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &Schema{Type: SchemaType{"integer"}, Minimum: &zero}
+	case reflect.Float32:
+		return &Schema{Type: SchemaType{"number"}, Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: SchemaType{"number"}, Format: "double"}
+	case reflect.String:
+		return &Schema{Type: SchemaType{"string"}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes []byte as a base64 string.
+			return &Schema{Type: SchemaType{"string"}, Format: "byte"}
+		}
+		return &Schema{Type: SchemaType{"array"}, Items: r.reflect(t.Elem())}
+	case reflect.Array:
+		n := t.Len()
+		return &Schema{Type: SchemaType{"array"}, Items: r.reflect(t.Elem()), MinItems: &n, MaxItems: &n}
+	case reflect.Map:
+		return &Schema{Type: SchemaType{"object"}, AdditionalProperties: r.reflect(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.reflectStruct(t)
+		}
+		name, ok := r.names[t]
+		if !ok {
+			name = r.schemaName(t)
+			r.names[t] = name
+			// Register the schema before reflecting the fields, so that a recursive type refers to itself.
+			s := &Schema{}
+			r.schemas[name] = s
+			*s = *r.reflectStruct(t)
+		}
+		return &Schema{Ref: schemaRefPrefix + name}
+	default:
+		// Interfaces can be any value. Channels and functions can't be encoded.
+		return &Schema{}
+	}
+}
+
+var schemaNameInvalidChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// schemaName returns a unique name of the schema of the named type t.
+func (r *SchemaReflector) schemaName(t reflect.Type) string {
+	base := strings.Trim(schemaNameInvalidChars.ReplaceAllString(t.Name(), "_"), "_")
+	if _, taken := r.schemas[base]; !taken {
+		return base
+	}
+	// Another package has a type with the same name.
+	base = path.Base(t.PkgPath()) + "." + base
+	name := base
+	for i := 2; ; i++ {
+		if _, taken := r.schemas[name]; !taken {
+			return name
+		}
+		name = base + strconv.Itoa(i)
+	}
+}
+
+// reflectStruct returns the schema of the struct type t.
+func (r *SchemaReflector) reflectStruct(t reflect.Type) *Schema {
+	s := &Schema{Type: SchemaType{"object"}}
+	for _, f := range structFields(t) {
+		fs, required := r.reflectField(f.field, f.opts)
+		if s.Properties == nil {
+			s.Properties = map[string]*Schema{}
+		}
+		s.Properties[f.name] = fs
+		if required {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s
+}
+
+// structField is a field of a struct type that encoding/json encodes.
+type structField struct {
+	name  string
+	opts  string
+	field reflect.StructField
+	// index is the index sequence of the field in the struct type, which is longer for a promoted field.
+	index []int
+	// tagged reports whether the name is given by the `json` tag.
+	tagged bool
+}
+
+// structFields returns the fields of the struct type t that encoding/json encodes, in the order of encoding.
+// The fields of embedded structs are promoted. Like encoding/json, of the fields with the same name, the shallowest
+// one takes precedence, and a tagged one at the same depth takes precedence over the untagged ones.
+// The fields are left out if there is still more than one of them.
+func structFields(t reflect.Type) []structField {
+	type embeddedStruct struct {
+		t     reflect.Type
+		index []int
+	}
+
+	var fields []structField
+	next := []embeddedStruct{{t: t}}
+	visited := map[reflect.Type]bool{}
+	for len(next) > 0 {
+		level := next
+		next = nil
+		// count is the number of times each type is embedded at this depth. The fields of a type embedded more than
+		// once are added as many times, so that they are ambiguous.
+		count := map[reflect.Type]int{}
+		for _, e := range level {
+			count[e.t]++
+		}
+		for _, e := range level {
+			if visited[e.t] {
+				continue
+			}
+			visited[e.t] = true
+
+			for i := 0; i < e.t.NumField(); i++ {
+				f := e.t.Field(i)
+				ft := f.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if f.Anonymous {
+					if !f.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !f.IsExported() {
+					continue
+				}
+				tag := f.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(slices.Clone(e.index), i)
+				if name == "" && f.Anonymous && ft.Kind() == reflect.Struct {
+					next = append(next, embeddedStruct{t: ft, index: index})
+					continue
+				}
+
+				field := structField{name: name, opts: opts, field: f, index: index, tagged: name != ""}
+				if field.name == "" {
+					field.name = f.Name
+				}
+				for range count[e.t] {
+					fields = append(fields, field)
+				}
+			}
+		}
+	}
+
+	// Sort the fields by name, depth and tag, so that the dominant field of each name comes first.
+	slices.SortStableFunc(fields, func(a, b structField) int {
+		if a.name != b.name {
+			return strings.Compare(a.name, b.name)
+		}
+		if len(a.index) != len(b.index) {
+			return len(a.index) - len(b.index)
+		}
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+			return 1
+		}
+		return slices.Compare(a.index, b.index)
+	})
+	var dominant []structField
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		if j-i == 1 || len(fields[i].index) != len(fields[i+1].index) || fields[i].tagged != fields[i+1].tagged {
+			dominant = append(dominant, fields[i])
+		}
+		i = j
+	}
+	slices.SortFunc(dominant, func(a, b structField) int {
+		return slices.Compare(a.index, b.index)
+	})
+	return dominant
+}
+
+// reflectField returns the schema of the struct field f with the options of its `json` tag,
+// and whether the field is required.
+func (r *SchemaReflector) reflectField(f reflect.StructField, opts string) (*Schema, bool) {
+	optional := false
+	asString := false
+	for _, opt := range strings.Split(opts, ",") {
+		switch opt {
+		case "omitempty", "omitzero":
+			optional = true
+		case "string":
+			asString = true
+		}
+	}
+
+	t := f.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s := r.reflect(t)
+	if asString {
+		switch t.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64, reflect.String:
+			// The value is encoded in a JSON string.
+			s = &Schema{Type: SchemaType{"string"}}
+		}
+	}
+
+	required := !optional
+	if tag, ok := f.Tag.Lookup("validate"); ok {
+		switch applyValidateTag(s, tag, t) {
+		case validateRequired:
+			required = true
+		case validateOptional:
+			required = false
+		}
+	}
+	if example, ok := f.Tag.Lookup("example"); ok {
+		s.Examples = append(s.Examples, parseSchemaExample(example, t))
+	}
+
+	if f.Type.Kind() == reflect.Pointer && !optional {
+		// encoding/json encodes a nil pointer as null.
+		if s.Ref != "" {
+			s = &Schema{AnyOf: []*Schema{s, {Type: SchemaType{"null"}}}}
+		} else if len(s.Type) > 0 {
+			s.Type = append(s.Type, "null")
+			if len(s.Enum) > 0 {
+				s.Enum = append(s.Enum, nil)
+			}
+		}
+	}
+	return s, required
+}
+
+// validatePresence is the presence of a field required by a `validate` tag.
+type validatePresence int
+
+const (
+	validateUnspecified validatePresence = iota
+	validateRequired
+	validateOptional
+)
+
+// applyValidateTag adds the constraints of the `validate` tag of a field of the type t to s,
+// and returns the presence of the field required by the tag.
+func applyValidateTag(s *Schema, tag string, t reflect.Type) validatePresence {
+	presence := validateUnspecified
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if strings.Contains(name, "|") {
+			// Alternatives can't be described by the schema.
+			continue
+		}
+		switch name {
+		case "dive", "keys":
+			// The following rules are for the elements.
+			return presence
+		case "required":
+			presence = validateRequired
+		case "omitempty":
+			presence = validateOptional
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			applySizeConstraint(s, name, n, t)
+		case "gt", "gte", "lt", "lte":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil || !isNumberKind(t.Kind()) {
+				continue
+			}
+			switch name {
+			case "gt":
+				s.ExclusiveMinimum = &n
+			case "gte":
+				s.Minimum = &n
+			case "lt":
+				s.ExclusiveMaximum = &n
+			case "lte":
+				s.Maximum = &n
+			}
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, parseSchemaExample(v, t))
+			}
+		case "email", "uuid", "ipv4", "ipv6", "hostname":
+			s.Format = name
+		case "uuid4", "uuid5", "uuid3":
+			s.Format = "uuid"
+		case "url", "uri", "http_url":
+			s.Format = "uri"
+		case "hostname_rfc1123":
+			s.Format = "hostname"
+		}
+	}
+	return presence
+}
+
+// applySizeConstraint adds the `min`, `max` or `len` constraint n of a field of the type t to s.
+// It is the length of a string or an array, or the value of a number.
+func applySizeConstraint(s *Schema, name string, n float64, t reflect.Type) {
+	switch {
+	case t.Kind() == reflect.String:
+		i := int(n)
+		if name != "max" {
+			s.MinLength = &i
+		}
+		if name != "min" {
+			s.MaxLength = &i
+		}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		i := int(n)
+		if name != "max" {
+			s.MinItems = &i
+		}
+		if name != "min" {
+			s.MaxItems = &i
+		}
+	case isNumberKind(t.Kind()):
+		if name != "max" {
+			s.Minimum = &n
+		}
+		if name != "min" {
+			s.Maximum = &n
+		}
+	}
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// parseSchemaExample parses the value of an `example` or `oneof` tag of a field of the type t.
+// A value of a type other than a string, a bool or a number is parsed as JSON, such as `["a", "b"]`.
+func parseSchemaExample(value string, t reflect.Type) any {
+	switch t.Kind() {
+	case reflect.String:
+		return value
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return u
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	default:
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err == nil {
+			return v
+		}
+	}
+	return value
+}
@@ -0,0 +1,267 @@
+package openapidocs
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+type schemaTestStatus string
+
+// schemaTestStatusValues is the backing array of the values of schemaTestStatus.
+// The spare elements are overwritten if the values are appended to.
+var schemaTestStatusValues = [4]any{"active", "inactive", "spare", "spare"}
+
+func (schemaTestStatus) EnumValues() []any {
+	return schemaTestStatusValues[:2]
+}
+
+type schemaTestUser struct {
+	ID        int64             `json:"id"`
+	Name      string            `json:"name" validate:"required,min=1,max=64" example:"Alice"`
+	Email     string            `json:"email,omitempty" validate:"email"`
+	Age       int               `json:"age" validate:"omitempty,gte=0,lt=150"`
+	Role      string            `json:"role" validate:"oneof=admin member"`
+	Status    schemaTestStatus  `json:"status"`
+	Previous  *schemaTestStatus `json:"previous"`
+	Tags      []string          `json:"tags" validate:"max=3,dive,min=1"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Avatar    []byte            `json:"avatar,omitempty"`
+	Manager   *schemaTestUser   `json:"manager"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Count     int               `json:"count,string"`
+	Extra     json.RawMessage   `json:"extra,omitempty"`
+	Ignored   string            `json:"-"`
+	internal  string
+}
+
+func TestSchemaReflectorReflect(t *testing.T) {
+	zero, three := 0.0, 3
+	tests := []struct {
+		name string
+		v    any
+		want *Schema
+	}{
+		{name: "bool", v: true, want: &Schema{Type: SchemaType{"boolean"}}},
+		{name: "int32", v: int32(0), want: &Schema{Type: SchemaType{"integer"}, Format: "int32"}},
+		{name: "uint", v: uint(0), want: &Schema{Type: SchemaType{"integer"}, Minimum: &zero}},
+		{name: "float64", v: 0.0, want: &Schema{Type: SchemaType{"number"}, Format: "double"}},
+		{name: "pointer", v: new(string), want: &Schema{Type: SchemaType{"string"}}},
+		{name: "bytes", v: []byte{}, want: &Schema{Type: SchemaType{"string"}, Format: "byte"}},
+		{name: "array", v: [3]int{}, want: &Schema{Type: SchemaType{"array"}, Items: &Schema{Type: SchemaType{"integer"}}, MinItems: &three, MaxItems: &three}},
+		{name: "map", v: map[string]bool{}, want: &Schema{Type: SchemaType{"object"}, AdditionalProperties: &Schema{Type: SchemaType{"boolean"}}}},
+		{name: "time", v: time.Time{}, want: &Schema{Type: SchemaType{"string"}, Format: "date-time"}},
+		{name: "json.Number", v: json.Number(""), want: &Schema{Type: SchemaType{"number"}}},
+		{name: "text marshaler", v: schemaTestIP{}, want: &Schema{Type: SchemaType{"string"}}},
+		{name: "enum", v: schemaTestStatus(""), want: &Schema{Type: SchemaType{"string"}, Enum: []any{"active", "inactive"}}},
+		{name: "nil", v: nil, want: &Schema{}},
+		{name: "reflect.Type", v: reflect.TypeFor[[]string](), want: &Schema{Type: SchemaType{"array"}, Items: &Schema{Type: SchemaType{"string"}}}},
+		{name: "anonymous struct", v: struct {
+			A string `json:"a"`
+		}{}, want: &Schema{Type: SchemaType{"object"}, Properties: map[string]*Schema{"a": {Type: SchemaType{"string"}}}, Required: []string{"a"}}},
+		{
+			name: "named struct",
+			v:    schemaTestUser{},
+			want: &Schema{Ref: "#/components/schemas/schemaTestUser"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewSchemaReflector().Reflect(tt.v)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reflect() = %s, want %s", schemaJSON(t, got), schemaJSON(t, tt.want))
+			}
+		})
+	}
+}
+
+// schemaTestIP implements encoding.TextMarshaler like net.IP.
+type schemaTestIP []byte
+
+func (ip schemaTestIP) MarshalText() ([]byte, error) {
+	return []byte("127.0.0.1"), nil
+}
+
+func TestSchemaReflectorStruct(t *testing.T) {
+	r := NewSchemaReflector()
+	r.Reflect(schemaTestUser{})
+	got := r.Schemas()["schemaTestUser"]
+
+	zero, oneFifty := 0.0, 150.0
+	one, sixtyFour, three := 1, 64, 3
+	want := &Schema{
+		Type: SchemaType{"object"},
+		Properties: map[string]*Schema{
+			"id":        {Type: SchemaType{"integer"}, Format: "int64"},
+			"name":      {Type: SchemaType{"string"}, MinLength: &one, MaxLength: &sixtyFour, Examples: []any{"Alice"}},
+			"email":     {Type: SchemaType{"string"}, Format: "email"},
+			"age":       {Type: SchemaType{"integer"}, Minimum: &zero, ExclusiveMaximum: &oneFifty},
+			"role":      {Type: SchemaType{"string"}, Enum: []any{"admin", "member"}},
+			"status":    {Type: SchemaType{"string"}, Enum: []any{"active", "inactive"}},
+			"previous":  {Type: SchemaType{"string", "null"}, Enum: []any{"active", "inactive", nil}},
+			"tags":      {Type: SchemaType{"array"}, Items: &Schema{Type: SchemaType{"string"}}, MaxItems: &three},
+			"labels":    {Type: SchemaType{"object"}, AdditionalProperties: &Schema{Type: SchemaType{"string"}}},
+			"avatar":    {Type: SchemaType{"string"}, Format: "byte"},
+			"manager":   {AnyOf: []*Schema{{Ref: "#/components/schemas/schemaTestUser"}, {Type: SchemaType{"null"}}}},
+			"createdAt": {Type: SchemaType{"string"}, Format: "date-time"},
+			"count":     {Type: SchemaType{"string"}},
+			"extra":     {},
+		},
+		Required: []string{"id", "name", "role", "status", "previous", "tags", "manager", "createdAt", "count"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("schema = %s, want %s", schemaJSON(t, got), schemaJSON(t, want))
+	}
+}
+
+func TestSchemaReflectorDoesNotModifyEnumValues(t *testing.T) {
+	type withEnums struct {
+		Status   schemaTestStatus  `json:"status" validate:"oneof=archived"`
+		Previous *schemaTestStatus `json:"previous"`
+	}
+	before := schemaTestStatusValues
+	r := NewSchemaReflector()
+	r.Reflect(withEnums{})
+	s := r.Schemas()["withEnums"]
+	if schemaTestStatusValues != before {
+		t.Errorf("the values of EnumValues() = %v, want %v", schemaTestStatusValues, before)
+	}
+	want := []any{"active", "inactive", "archived"}
+	if got := s.Properties["status"].Enum; !slices.Equal(got, want) {
+		t.Errorf("enum = %v, want %v", got, want)
+	}
+}
+
+type schemaTestA struct {
+	A    string `json:"a"`
+	Deep string `json:"deep"`
+}
+
+type schemaTestB struct {
+	B    string `json:"b"`
+	Both string
+}
+
+type schemaTestC struct {
+	Both string
+	schemaTestA
+}
+
+type schemaTestD struct {
+	schemaTestA
+}
+
+type schemaTestTagged struct {
+	Name string `json:"Name"`
+}
+
+type schemaTestUntagged struct {
+	Name string
+}
+
+func TestSchemaReflectorFieldPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		// want is the properties in the order of the fields, which are also the keys encoded by encoding/json.
+		want []string
+	}{
+		{
+			name: "shallower field takes precedence",
+			v: struct {
+				A string `json:"a"`
+				schemaTestA
+			}{},
+			want: []string{"a", "deep"},
+		},
+		{
+			name: "deeper field of an earlier embedded struct doesn't hide a shallower one",
+			v: struct {
+				schemaTestC
+				schemaTestA
+			}{},
+			want: []string{"Both", "a", "deep"},
+		},
+		{
+			name: "fields with the same name at the same depth are dropped",
+			v: struct {
+				schemaTestB
+				schemaTestC
+			}{},
+			want: []string{"b", "a", "deep"},
+		},
+		{
+			name: "tagged field takes precedence at the same depth",
+			v: struct {
+				schemaTestUntagged
+				schemaTestTagged
+			}{},
+			want: []string{"Name"},
+		},
+		{
+			name: "embedded struct with a tag is a field",
+			v: struct {
+				schemaTestA `json:"nested"`
+				B           string `json:"b"`
+			}{},
+			want: []string{"nested", "b"},
+		},
+		{
+			name: "same struct embedded twice at the same depth",
+			v: struct {
+				schemaTestC
+				schemaTestD
+			}{},
+			want: []string{"Both"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSchemaReflector().Reflect(tt.v)
+			if got := slices.Sorted(maps.Keys(s.Properties)); !slices.Equal(got, slices.Sorted(slices.Values(tt.want))) {
+				t.Errorf("properties = %v, want %v", got, tt.want)
+			}
+			if !slices.Equal(s.Required, tt.want) {
+				t.Errorf("required = %v, want %v", s.Required, tt.want)
+			}
+
+			// encoding/json encodes the same fields.
+			data, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var encoded map[string]any
+			if err := json.Unmarshal(data, &encoded); err != nil {
+				t.Fatal(err)
+			}
+			if got := slices.Sorted(maps.Keys(encoded)); !slices.Equal(got, slices.Sorted(slices.Values(tt.want))) {
+				t.Errorf("encoding/json encodes %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaReflectorRecursiveEmbedding(t *testing.T) {
+	type node struct {
+		Value string `json:"value"`
+		*node
+	}
+	r := NewSchemaReflector()
+	r.Reflect(node{})
+	s := r.Schemas()["node"]
+	if len(s.Properties) != 1 || s.Properties["value"] == nil {
+		t.Errorf("properties = %v, want only value", s.Properties)
+	}
+}
+
+func schemaJSON(t *testing.T, s *Schema) string {
+	t.Helper()
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
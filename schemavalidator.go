@@ -0,0 +1,508 @@
+package openapidocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// schemaValidator validates JSON values against the schemas of an OpenAPI specification.
+// The values and the specification are decoded with json.Decoder.UseNumber.
+// It supports the keywords of OpenAPI 3.0 and 3.1 schemas that constrain values, and ignores annotations such as
+// `format`, `discriminator` and `example`. Local references such as `#/components/schemas/User` are resolved
+// in the specification, and the other references accept any value.
+type schemaValidator struct {
+	// root is the specification.
+	root any
+	// openAPI31 reports whether the specification is OpenAPI 3.1, in which the siblings of `$ref` are applied
+	// and `exclusiveMinimum` and `exclusiveMaximum` are numbers.
+	openAPI31 bool
+
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+// schemaViolation is a value that doesn't satisfy a schema.
+type schemaViolation struct {
+	// pointer is the JSON pointer of the value, such as `/items/0/name`.
+	pointer string
+	message string
+}
+
+// schemaDirection is the direction of the values validated against a schema.
+// The `readOnly` properties are not required in requests, and the `writeOnly` properties are not required in responses.
+type schemaDirection int
+
+const (
+	schemaDirectionRequest schemaDirection = iota
+	schemaDirectionResponse
+)
+
+// maxSchemaRefDepth is the maximum number of references followed without going into a value,
+// to stop at a reference cycle such as `A: {$ref: B}` and `B: {$ref: A}`.
+const maxSchemaRefDepth = 32
+
+func newSchemaValidator(root any) *schemaValidator {
+	v := &schemaValidator{
+		root:     root,
+		patterns: map[string]*regexp.Regexp{},
+	}
+	if m, ok := root.(map[string]any); ok {
+		if version, ok := m["openapi"].(string); ok {
+			v.openAPI31 = !strings.HasPrefix(version, "3.0")
+		}
+	}
+	return v
+}
+
+// validate returns the violations of value against schema. pointer is the JSON pointer of value.
+func (v *schemaValidator) validate(schema, value any, pointer string, dir schemaDirection) []schemaViolation {
+	c := &schemaCheck{v: v, dir: dir}
+	c.check(schema, value, pointer, 0)
+	return c.violations
+}
+
+// resolve returns the value the local reference ref points to in the specification, or nil if it can't be resolved.
+func (v *schemaValidator) resolve(ref string) any {
+	fragment, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil
+	}
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+	node := v.root
+	if fragment == "" {
+		return node
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(fragment, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch n := node.(type) {
+		case map[string]any:
+			node = n[token]
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil
+			}
+			node = n[i]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// deref returns the object that node refers to with `$ref`, or node itself if it is not a reference.
+func (v *schemaValidator) deref(node any) map[string]any {
+	for i := 0; i < maxSchemaRefDepth; i++ {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m
+		}
+		node = v.resolve(ref)
+	}
+	return nil
+}
+
+// pattern returns the compiled pattern, or nil if it is not supported by regexp.
+func (v *schemaValidator) pattern(p string) *regexp.Regexp {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	re, ok := v.patterns[p]
+	if !ok {
+		re, _ = regexp.Compile(p)
+		v.patterns[p] = re
+	}
+	return re
+}
+
+// schemaCheck is a validation of a value against a schema.
+type schemaCheck struct {
+	v          *schemaValidator
+	dir        schemaDirection
+	violations []schemaViolation
+}
+
+func (c *schemaCheck) errorf(pointer, format string, args ...any) {
+	c.violations = append(c.violations, schemaViolation{pointer: pointer, message: fmt.Sprintf(format, args...)})
+}
+
+// passes reports whether value satisfies schema, without recording the violations.
+func (c *schemaCheck) passes(schema, value any, pointer string, depth int) bool {
+	sub := &schemaCheck{v: c.v, dir: c.dir}
+	sub.check(schema, value, pointer, depth)
+	return len(sub.violations) == 0
+}
+
+// check validates value against schema. depth is the number of references followed for value.
+func (c *schemaCheck) check(schema, value any, pointer string, depth int) {
+	if b, ok := schema.(bool); ok {
+		if !b {
+			c.errorf(pointer, "is not allowed")
+		}
+		return
+	}
+	s, ok := schema.(map[string]any)
+	if !ok {
+		return
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		if depth >= maxSchemaRefDepth {
+			return
+		}
+		if target := c.v.resolve(ref); target != nil {
+			c.check(target, value, pointer, depth+1)
+		}
+		if !c.v.openAPI31 {
+			// The siblings of $ref are ignored in OpenAPI 3.0.
+			return
+		}
+	}
+
+	if types := schemaTypes(s); len(types) > 0 && !matchesSchemaType(value, types) {
+		c.errorf(pointer, "must be %s", strings.Join(types, " or "))
+		return
+	}
+	if enum, ok := s["enum"].([]any); ok && !containsJSONValue(enum, value) {
+		c.errorf(pointer, "must be one of %s", formatJSONValues(enum))
+	}
+	if constValue, ok := s["const"]; ok && !equalJSONValues(constValue, value) {
+		c.errorf(pointer, "must be %s", formatJSONValues([]any{constValue}))
+	}
+
+	switch value := value.(type) {
+	case json.Number:
+		c.checkNumber(s, value, pointer)
+	case string:
+		c.checkString(s, value, pointer)
+	case []any:
+		c.checkArray(s, value, pointer)
+	case map[string]any:
+		c.checkObject(s, value, pointer)
+	}
+
+	if allOf, ok := s["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			c.check(sub, value, pointer, depth)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if c.passes(sub, value, pointer, depth) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			c.errorf(pointer, "must match at least one schema of anyOf")
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			if c.passes(sub, value, pointer, depth) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			c.errorf(pointer, "must match exactly one schema of oneOf, but matches %d", matched)
+		}
+	}
+	if not, ok := s["not"]; ok && c.passes(not, value, pointer, depth) {
+		c.errorf(pointer, "must not match the schema of not")
+	}
+}
+
+func (c *schemaCheck) checkNumber(s map[string]any, value json.Number, pointer string) {
+	n, err := value.Float64()
+	if err != nil {
+		return
+	}
+	if min, ok := schemaNumber(s, "minimum"); ok {
+		if exclusive, _ := s["exclusiveMinimum"].(bool); exclusive && n <= min {
+			c.errorf(pointer, "must be > %v", min)
+		} else if n < min {
+			c.errorf(pointer, "must be >= %v", min)
+		}
+	}
+	if max, ok := schemaNumber(s, "maximum"); ok {
+		if exclusive, _ := s["exclusiveMaximum"].(bool); exclusive && n >= max {
+			c.errorf(pointer, "must be < %v", max)
+		} else if n > max {
+			c.errorf(pointer, "must be <= %v", max)
+		}
+	}
+	if min, ok := schemaNumber(s, "exclusiveMinimum"); ok && n <= min {
+		c.errorf(pointer, "must be > %v", min)
+	}
+	if max, ok := schemaNumber(s, "exclusiveMaximum"); ok && n >= max {
+		c.errorf(pointer, "must be < %v", max)
+	}
+	if m, ok := schemaNumber(s, "multipleOf"); ok && m > 0 {
+		q := n / m
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			c.errorf(pointer, "must be a multiple of %v", m)
+		}
+	}
+}
+
+func (c *schemaCheck) checkString(s map[string]any, value string, pointer string) {
+	length := utf8.RuneCountInString(value)
+	if min, ok := schemaNumber(s, "minLength"); ok && float64(length) < min {
+		c.errorf(pointer, "must be at least %v characters long", min)
+	}
+	if max, ok := schemaNumber(s, "maxLength"); ok && float64(length) > max {
+		c.errorf(pointer, "must be at most %v characters long", max)
+	}
+	if p, ok := s["pattern"].(string); ok {
+		if re := c.v.pattern(p); re != nil && !re.MatchString(value) {
+			c.errorf(pointer, "must match the pattern %q", p)
+		}
+	}
+}
+
+func (c *schemaCheck) checkArray(s map[string]any, value []any, pointer string) {
+	if min, ok := schemaNumber(s, "minItems"); ok && float64(len(value)) < min {
+		c.errorf(pointer, "must have at least %v items", min)
+	}
+	if max, ok := schemaNumber(s, "maxItems"); ok && float64(len(value)) > max {
+		c.errorf(pointer, "must have at most %v items", max)
+	}
+	if unique, _ := s["uniqueItems"].(bool); unique {
+		for i := 1; i < len(value); i++ {
+			if containsJSONValue(value[:i], value[i]) {
+				c.errorf(pointer, "must have unique items")
+				break
+			}
+		}
+	}
+
+	start := 0
+	if prefixItems, ok := s["prefixItems"].([]any); ok {
+		for i := 0; i < len(prefixItems) && i < len(value); i++ {
+			c.check(prefixItems[i], value[i], pointer+"/"+strconv.Itoa(i), 0)
+		}
+		start = len(prefixItems)
+	}
+	if items, ok := s["items"]; ok {
+		for i := start; i < len(value); i++ {
+			c.check(items, value[i], pointer+"/"+strconv.Itoa(i), 0)
+		}
+	}
+}
+
+func (c *schemaCheck) checkObject(s map[string]any, value map[string]any, pointer string) {
+	if min, ok := schemaNumber(s, "minProperties"); ok && float64(len(value)) < min {
+		c.errorf(pointer, "must have at least %v properties", min)
+	}
+	if max, ok := schemaNumber(s, "maxProperties"); ok && float64(len(value)) > max {
+		c.errorf(pointer, "must have at most %v properties", max)
+	}
+
+	properties, _ := s["properties"].(map[string]any)
+	if required, ok := s["required"].([]any); ok {
+		for _, r := range required {
+			name, ok := r.(string)
+			if !ok {
+				continue
+			}
+			if _, ok := value[name]; ok || c.ignoresRequired(properties[name]) {
+				continue
+			}
+			c.errorf(pointer, "must have the property %q", name)
+		}
+	}
+
+	// Check the properties in order, so that the violations are reported in a stable order.
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	patternProperties, _ := s["patternProperties"].(map[string]any)
+	additional, hasAdditional := s["additionalProperties"]
+	for _, name := range names {
+		p := pointer + "/" + escapeJSONPointer(name)
+		matched := false
+		if sub, ok := properties[name]; ok {
+			c.check(sub, value[name], p, 0)
+			matched = true
+		}
+		for pattern, sub := range patternProperties {
+			if re := c.v.pattern(pattern); re != nil && re.MatchString(name) {
+				c.check(sub, value[name], p, 0)
+				matched = true
+			}
+		}
+		if matched || !hasAdditional {
+			continue
+		}
+		if b, ok := additional.(bool); ok {
+			if !b {
+				c.errorf(pointer, "must not have the property %q", name)
+			}
+			continue
+		}
+		c.check(additional, value[name], p, 0)
+	}
+}
+
+// ignoresRequired reports whether a required property of the schema is not required in the direction of the check.
+func (c *schemaCheck) ignoresRequired(schema any) bool {
+	s := c.v.deref(schema)
+	if s == nil {
+		return false
+	}
+	switch c.dir {
+	case schemaDirectionRequest:
+		readOnly, _ := s["readOnly"].(bool)
+		return readOnly
+	default:
+		writeOnly, _ := s["writeOnly"].(bool)
+		return writeOnly
+	}
+}
+
+// schemaTypes returns the types that the schema allows. `nullable` of OpenAPI 3.0 allows null.
+func schemaTypes(s map[string]any) []string {
+	var types []string
+	switch t := s["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if name, ok := v.(string); ok {
+				types = append(types, name)
+			}
+		}
+	}
+	if nullable, _ := s["nullable"].(bool); nullable && len(types) > 0 {
+		types = append(types, "null")
+	}
+	return types
+}
+
+// matchesSchemaType reports whether value is of one of the types. An integer is also a number.
+func matchesSchemaType(value any, types []string) bool {
+	actual := jsonValueType(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonValueType returns the JSON Schema type of value.
+func jsonValueType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if f, err := value.Float64(); err == nil && f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+// schemaNumber returns the number value of key in the schema.
+func schemaNumber(s map[string]any, key string) (float64, bool) {
+	n, ok := s[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+// equalJSONValues reports whether the JSON values are equal. Numbers are compared by their values.
+func equalJSONValues(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		bn, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, aErr := a.Float64()
+		bf, bErr := bn.Float64()
+		return aErr == nil && bErr == nil && af == bf
+	case []any:
+		bs, ok := b.([]any)
+		if !ok || len(a) != len(bs) {
+			return false
+		}
+		for i := range a {
+			if !equalJSONValues(a[i], bs[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bm, ok := b.(map[string]any)
+		if !ok || len(a) != len(bm) {
+			return false
+		}
+		for k, v := range a {
+			bv, ok := bm[k]
+			if !ok || !equalJSONValues(v, bv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func containsJSONValue(values []any, value any) bool {
+	for _, v := range values {
+		if equalJSONValues(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatJSONValues formats the values for a message, such as `["a", "b"]`.
+func formatJSONValues(values []any) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Sprint(values)
+	}
+	s := string(data)
+	if len(values) == 1 {
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	}
+	return s
+}
+
+// escapeJSONPointer escapes a reference token of a JSON pointer.
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
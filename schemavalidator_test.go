@@ -0,0 +1,216 @@
+package openapidocs
+
+import (
+	"reflect"
+	"testing"
+)
+
+// schemaValidatorTestComponents is the components of the specifications of the tests of schemaValidator.
+const schemaValidatorTestComponents = `{
+  "schemas": {
+    "User": {
+      "type": "object",
+      "required": ["id", "name", "password"],
+      "properties": {
+        "id": {"type": "integer", "readOnly": true},
+        "name": {"type": "string"},
+        "password": {"type": "string", "writeOnly": true}
+      }
+    },
+    "Name": {"type": "string", "minLength": 2},
+    "A": {"$ref": "#/components/schemas/B"},
+    "B": {"$ref": "#/components/schemas/A"},
+    "Node": {
+      "type": "object",
+      "properties": {
+        "value": {"type": "integer"},
+        "children": {"type": "array", "items": {"$ref": "#/components/schemas/Node"}}
+      }
+    }
+  }
+}`
+
+func TestSchemaCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		openAPI31 bool
+		schema    string
+		value     string
+		dir       schemaDirection
+		want      []schemaViolation
+	}{
+		{name: "type", schema: `{"type": "string"}`, value: `1`, want: []schemaViolation{{message: "must be string"}}},
+		{name: "integer is a number", schema: `{"type": "number"}`, value: `1`},
+		{name: "number is not an integer", schema: `{"type": "integer"}`, value: `1.5`, want: []schemaViolation{{message: "must be integer"}}},
+		{name: "nullable", schema: `{"type": "string", "nullable": true}`, value: `null`},
+		{name: "null without nullable", schema: `{"type": "string"}`, value: `null`, want: []schemaViolation{{message: "must be string"}}},
+		{name: "type list", openAPI31: true, schema: `{"type": ["string", "null"]}`, value: `null`},
+		{name: "enum", schema: `{"enum": ["a", "b"]}`, value: `"c"`, want: []schemaViolation{{message: `must be one of ["a","b"]`}}},
+		{name: "const compares numbers by value", openAPI31: true, schema: `{"const": 1}`, value: `1.0`},
+		{name: "const", openAPI31: true, schema: `{"const": 1}`, value: `2`, want: []schemaViolation{{message: "must be 1"}}},
+		{name: "boolean exclusiveMinimum", schema: `{"minimum": 1, "exclusiveMinimum": true}`, value: `1`, want: []schemaViolation{{message: "must be > 1"}}},
+		{name: "number exclusiveMinimum", openAPI31: true, schema: `{"exclusiveMinimum": 1}`, value: `1`, want: []schemaViolation{{message: "must be > 1"}}},
+		{name: "maximum", schema: `{"maximum": 10}`, value: `11`, want: []schemaViolation{{message: "must be <= 10"}}},
+		{name: "multipleOf of a fraction", schema: `{"multipleOf": 0.1}`, value: `0.3`},
+		{name: "multipleOf", schema: `{"multipleOf": 0.1}`, value: `0.35`, want: []schemaViolation{{message: "must be a multiple of 0.1"}}},
+		{
+			name:   "string",
+			schema: `{"minLength": 2, "maxLength": 3, "pattern": "^[a-z]+$"}`,
+			value:  `"日本語x"`,
+			want: []schemaViolation{
+				{message: "must be at most 3 characters long"},
+				{message: `must match the pattern "^[a-z]+$"`},
+			},
+		},
+		{name: "unsupported pattern", schema: `{"pattern": "^(?=a)"}`, value: `"b"`},
+		{
+			name:   "array",
+			schema: `{"type": "array", "items": {"type": "integer"}, "minItems": 1, "uniqueItems": true}`,
+			value:  `[1, 1, "x"]`,
+			want: []schemaViolation{
+				{message: "must have unique items"},
+				{pointer: "/2", message: "must be integer"},
+			},
+		},
+		{
+			name:      "prefixItems",
+			openAPI31: true,
+			schema:    `{"prefixItems": [{"type": "string"}], "items": {"type": "integer"}}`,
+			value:     `["a", 1, "b"]`,
+			want:      []schemaViolation{{pointer: "/2", message: "must be integer"}},
+		},
+		{name: "false schema", openAPI31: true, schema: `{"items": false}`, value: `[1]`, want: []schemaViolation{{pointer: "/0", message: "is not allowed"}}},
+		{
+			name:   "additionalProperties false",
+			schema: `{"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": false}`,
+			value:  `{"a": "x", "b": 1}`,
+			want:   []schemaViolation{{message: `must not have the property "b"`}},
+		},
+		{
+			name:   "patternProperties and additionalProperties",
+			schema: `{"patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": {"type": "integer"}}`,
+			value:  `{"x-a": 1, "b": "s"}`,
+			want: []schemaViolation{
+				{pointer: "/b", message: "must be integer"},
+				{pointer: "/x-a", message: "must be string"},
+			},
+		},
+		{name: "escaped pointer", schema: `{"properties": {"a/b": {"type": "string"}}}`, value: `{"a/b": 1}`, want: []schemaViolation{{pointer: "/a~1b", message: "must be string"}}},
+		{name: "readOnly in a request", schema: `{"$ref": "#/components/schemas/User"}`, value: `{"name": "a", "password": "p"}`, dir: schemaDirectionRequest},
+		{
+			name:   "readOnly in a response",
+			schema: `{"$ref": "#/components/schemas/User"}`,
+			value:  `{"name": "a", "password": "p"}`,
+			dir:    schemaDirectionResponse,
+			want:   []schemaViolation{{message: `must have the property "id"`}},
+		},
+		{name: "writeOnly in a response", schema: `{"$ref": "#/components/schemas/User"}`, value: `{"id": 1, "name": "a"}`, dir: schemaDirectionResponse},
+		{
+			name:   "writeOnly in a request",
+			schema: `{"$ref": "#/components/schemas/User"}`,
+			value:  `{"id": 1, "name": "a"}`,
+			dir:    schemaDirectionRequest,
+			want:   []schemaViolation{{message: `must have the property "password"`}},
+		},
+		{
+			name:   "reference",
+			schema: `{"type": "object", "properties": {"name": {"$ref": "#/components/schemas/Name"}}}`,
+			value:  `{"name": "a"}`,
+			want:   []schemaViolation{{pointer: "/name", message: "must be at least 2 characters long"}},
+		},
+		{name: "siblings of a reference in OpenAPI 3.0", schema: `{"$ref": "#/components/schemas/Name", "maxLength": 1}`, value: `"abc"`},
+		{
+			name:      "siblings of a reference in OpenAPI 3.1",
+			openAPI31: true,
+			schema:    `{"$ref": "#/components/schemas/Name", "maxLength": 1}`,
+			value:     `"abc"`,
+			want:      []schemaViolation{{message: "must be at most 1 characters long"}},
+		},
+		{name: "reference cycle", schema: `{"$ref": "#/components/schemas/A"}`, value: `1`},
+		{
+			name:   "recursive schema",
+			schema: `{"$ref": "#/components/schemas/Node"}`,
+			value:  `{"children": [{"children": [{"value": "x"}]}]}`,
+			want:   []schemaViolation{{pointer: "/children/0/children/0/value", message: "must be integer"}},
+		},
+		{name: "external reference", schema: `{"$ref": "other.yaml#/User"}`, value: `1`},
+		{
+			name:   "allOf",
+			schema: `{"allOf": [{"type": "object", "required": ["a"]}, {"required": ["b"]}]}`,
+			value:  `{}`,
+			want: []schemaViolation{
+				{message: `must have the property "a"`},
+				{message: `must have the property "b"`},
+			},
+		},
+		{name: "anyOf", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, value: `1`},
+		{name: "anyOf without a match", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, value: `true`, want: []schemaViolation{{message: "must match at least one schema of anyOf"}}},
+		{name: "oneOf", schema: `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, value: `1.5`},
+		{
+			name:   "oneOf with two matches",
+			schema: `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`,
+			value:  `1`,
+			want:   []schemaViolation{{message: "must match exactly one schema of oneOf, but matches 2"}},
+		},
+		{
+			name:   "oneOf without a match",
+			schema: `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`,
+			value:  `"x"`,
+			want:   []schemaViolation{{message: "must match exactly one schema of oneOf, but matches 0"}},
+		},
+		{
+			name:   "oneOf with references",
+			schema: `{"oneOf": [{"$ref": "#/components/schemas/Name"}, {"type": "integer"}]}`,
+			value:  `"a"`,
+			want:   []schemaViolation{{message: "must match exactly one schema of oneOf, but matches 0"}},
+		},
+		{name: "not", schema: `{"not": {"type": "string"}}`, value: `"x"`, want: []schemaViolation{{message: "must not match the schema of not"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := decodeTestJSON(t, `{"components": `+schemaValidatorTestComponents+`}`).(map[string]any)
+			root["openapi"] = "3.0.3"
+			if tt.openAPI31 {
+				root["openapi"] = "3.1.0"
+			}
+			v := newSchemaValidator(root)
+			got := v.validate(decodeTestJSON(t, tt.schema), decodeTestJSON(t, tt.value), "", tt.dir)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaValidatorResolve(t *testing.T) {
+	root := decodeTestJSON(t, `{"a": {"b/c": {"d~e": [1, 2]}}}`)
+	v := newSchemaValidator(root)
+	tests := []struct {
+		ref  string
+		want any
+	}{
+		{ref: "#/a/b~1c/d~0e/1", want: decodeTestJSON(t, `2`)},
+		{ref: "#/a/b%7E1c/d~0e/0", want: decodeTestJSON(t, `1`)},
+		{ref: "#", want: root},
+		{ref: "#/a/missing", want: nil},
+		{ref: "#/a/b~1c/d~0e/2", want: nil},
+		{ref: "other.yaml#/a", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if got := v.resolve(tt.ref); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolve(%q) = %v, want %v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+// decodeTestJSON decodes data in the same way as the specifications and the values are decoded for the validation.
+func decodeTestJSON(t *testing.T, data string) any {
+	t.Helper()
+	v, err := decodeJSONValue([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
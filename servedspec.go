@@ -0,0 +1,254 @@
+package openapidocs
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// servedSpec is a specification of a documentation site.
+type servedSpec struct {
+	name string
+	// title is Title of the NamedSpec. It may be empty.
+	title string
+	// url is SpecUrl of the configuration. It is empty if the specification is served by the site.
+	url string
+	// path is the path under the base path where the specification is served.
+	path string
+	// loadedSpec is the document of the specification, which may be shared with other sites and the validators
+	// by a SharedSpec. Its document is nil if the specification is given by url.
+	*loadedSpec
+}
+
+// loadedSpec is the current document of a specification, which is reloaded from its source if it is watched.
+type loadedSpec struct {
+	// label identifies the specification in the logs.
+	label string
+	// doc is the current document. It is nil if the specification is not served.
+	// It is replaced atomically when the specification is reloaded from source.
+	doc atomic.Pointer[specDocument]
+
+	// source is the source of the specification, which is watched if watch is true.
+	source   SpecSource
+	validate func(root *yaml.Node) error
+	// version is the version of source that was loaded last, including a version that failed to load.
+	// It is accessed only by the goroutine that reloads the specification.
+	version string
+	// mu guards the fields below.
+	mu    sync.Mutex
+	watch bool
+	// reloading reports whether a goroutine is reloading the specification.
+	reloading bool
+	// checked is the time when source was checked last.
+	checked time.Time
+	// lastErr is the message of the last error of the reloads, which is logged only once.
+	// It is cleared when the specification is checked successfully.
+	lastErr string
+}
+
+// newLoadedSpec returns the loadedSpec of the specification given by spec or source.
+func newLoadedSpec(label, spec string, source SpecSource, validate func(root *yaml.Node) error, watch bool) (*loadedSpec, error) {
+	s := &loadedSpec{
+		label:    label,
+		source:   source,
+		validate: validate,
+		watch:    watch,
+	}
+	if source != nil {
+		doc, version, err := loadSpecSource(source, validate)
+		if err != nil {
+			return nil, err
+		}
+		s.doc.Store(doc)
+		s.version = version
+		s.checked = time.Now()
+		return s, nil
+	}
+
+	doc, err := newSpecDocument(spec, validate)
+	if err != nil {
+		return nil, err
+	}
+	s.doc.Store(doc)
+	return s, nil
+}
+
+// served reports whether the specification is served by the site.
+func (s *loadedSpec) served() bool {
+	return s.doc.Load() != nil
+}
+
+// specUrl returns the URL of the specification for a page served at basePath.
+func (s *servedSpec) specUrl(basePath string) string {
+	if !s.served() {
+		return s.url
+	}
+	return path.Join(basePath, s.path)
+}
+
+// specJSON returns the specification in JSON, or nil if it is not available.
+func (s *loadedSpec) specJSON() []byte {
+	doc := s.doc.Load()
+	if doc == nil || doc.json == nil {
+		return nil
+	}
+	return doc.json.body
+}
+
+// etag returns the ETag of the current document, which identifies its content, or an empty string if it is not served.
+func (s *loadedSpec) etag() string {
+	doc := s.doc.Load()
+	switch {
+	case doc == nil:
+		return ""
+	case doc.json != nil:
+		return doc.json.etag
+	default:
+		return doc.raw.etag
+	}
+}
+
+// reload reloads the specification from source in a background goroutine if it is watched and
+// the interval has passed since the last check. Requests keep using the current document until the new one is loaded.
+// If the new specification can't be loaded, parsed or validated, the current document is kept and the error is logged
+// with logger. The same error is logged only once, until the specification is checked successfully.
+func (s *loadedSpec) reload(logger echo.Logger) {
+	if s.source == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if !s.watch || s.reloading || now.Sub(s.checked) < specWatchInterval {
+		return
+	}
+	s.reloading = true
+	s.checked = now
+
+	go func() {
+		err := s.load()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.reloading = false
+		if err == nil {
+			s.lastErr = ""
+			return
+		}
+		if msg := err.Error(); msg != s.lastErr {
+			s.lastErr = msg
+			logger.Errorf("openapidocs: %s: %v", s.label, err)
+		}
+	}()
+}
+
+// load loads the specification from source if its version has changed.
+func (s *loadedSpec) load() error {
+	version, err := s.source.Version()
+	if err != nil {
+		return fmt.Errorf("failed to reload the specification: %w", err)
+	}
+	if version == s.version {
+		return nil
+	}
+	s.version = version
+
+	doc, _, err := loadSpecSource(s.source, s.validate)
+	if err != nil {
+		return fmt.Errorf("failed to reload the specification: %w", err)
+	}
+	if doc.format == specFormatUnknown && s.doc.Load().format != specFormatUnknown {
+		// The file is probably being edited. Keep the last good version.
+		return fmt.Errorf("failed to reload the specification: %w", errSpecNotParsed)
+	}
+	s.doc.Store(doc)
+	return nil
+}
+
+// enableWatch makes the specification reloaded from source when it changes.
+func (s *loadedSpec) enableWatch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watch = true
+}
+
+// serve writes the current document of the specification in the format.
+func (s *servedSpec) serve(c echo.Context, format specFormat, cacheControl string) error {
+	reloadSpec(c, s.loadedSpec)
+	return s.doc.Load().serve(c, format, cacheControl)
+}
+
+// reloadSpec reloads s for the request in the background.
+func reloadSpec(c echo.Context, s *loadedSpec) {
+	s.reload(c.Logger())
+}
+
+// newServedSpecs returns the specifications of a documentation site configured with config.
+// A site that hosts a single specification has one servedSpec without a name.
+func newServedSpecs(config DocumentsConfig, kind specKind) ([]*servedSpec, error) {
+	var validate func(root *yaml.Node) error
+	if config.ValidateSpec {
+		validate = kind.validate
+	}
+	// The live reload needs the changes of the specification.
+	watch := config.WatchSpec || config.LiveReload
+
+	if len(config.Specs) == 0 {
+		ns := NamedSpec{Spec: config.Spec, SpecUrl: config.SpecUrl, SpecSource: config.SpecSource, SharedSpec: config.SharedSpec}
+		s, err := newServedSpec(ns, kind.path, validate, watch)
+		if err != nil {
+			return nil, err
+		}
+		return []*servedSpec{s}, nil
+	}
+
+	specs := make([]*servedSpec, 0, len(config.Specs))
+	names := map[string]bool{}
+	for _, ns := range config.Specs {
+		if !specNamePattern.MatchString(ns.Name) {
+			return nil, fmt.Errorf("invalid spec name %q: it may contain only letters, digits, '-', '_' and '.'", ns.Name)
+		}
+		if names[ns.Name] {
+			return nil, fmt.Errorf("duplicate spec name %q", ns.Name)
+		}
+		names[ns.Name] = true
+
+		s, err := newServedSpec(ns, path.Join(kind.path, ns.Name), validate, watch)
+		if err != nil {
+			return nil, fmt.Errorf("spec %q: %w", ns.Name, err)
+		}
+		specs = append(specs, s)
+	}
+	return specs, nil
+}
+
+// newServedSpec returns the servedSpec of ns served at specPath.
+// The document of a SharedSpec is shared, and it is watched if watch is true.
+func newServedSpec(ns NamedSpec, specPath string, validate func(root *yaml.Node) error, watch bool) (*servedSpec, error) {
+	s := &servedSpec{
+		name:  ns.Name,
+		title: ns.Title,
+		path:  specPath,
+	}
+	switch {
+	case ns.SharedSpec != nil:
+		s.loadedSpec = ns.SharedSpec.spec
+		if watch {
+			s.enableWatch()
+		}
+	case ns.SpecSource != nil || ns.Spec != "":
+		var err error
+		if s.loadedSpec, err = newLoadedSpec(specPath, ns.Spec, ns.SpecSource, validate, watch); err != nil {
+			return nil, err
+		}
+	case ns.SpecUrl != "":
+		s.url = ns.SpecUrl
+		s.loadedSpec = &loadedSpec{label: specPath}
+	default:
+		return nil, ErrSpecNotSet
+	}
+	return s, nil
+}
@@ -0,0 +1,74 @@
+package openapidocs
+
+import (
+	"gopkg.in/yaml.v3"
+	"sync"
+)
+
+// SharedSpecConfig is the configuration for NewSharedSpec.
+type SharedSpecConfig struct {
+	// Spec is the OpenAPI specification. It must be in JSON or YAML.
+	Spec string
+	// SpecSource is the source of the specification, such as a file. If it is not nil, Spec is ignored.
+	SpecSource SpecSource
+	// WatchSpec makes the specification reloaded when SpecSource changes. It is meant for development.
+	// It is also enabled by a documentation site with LiveReload that uses the specification.
+	WatchSpec bool
+	// ValidateSpec makes NewSharedSpec validate the specification as an OpenAPI 3.0, 3.1 or Swagger 2.0 document.
+	ValidateSpec bool
+}
+
+var DefaultSharedSpecConfig = SharedSpecConfig{
+	Spec:         "",
+	SpecSource:   nil,
+	WatchSpec:    false,
+	ValidateSpec: false,
+}
+
+// SharedSpec is an OpenAPI specification shared by documentation sites and the validation middlewares.
+// The specification is loaded, parsed and reloaded once for all of them, and they always use the same version of it.
+// Set it to SharedSpec of the configurations.
+type SharedSpec struct {
+	spec *loadedSpec
+
+	once      sync.Once
+	validated *validatedSpec
+	err       error
+}
+
+// NewSharedSpec returns a SharedSpec of the specification configured with config.
+// It returns an error if the specification can't be loaded, or is invalid and ValidateSpec is true.
+func NewSharedSpec(config SharedSpecConfig) (*SharedSpec, error) {
+	if config.Spec == "" && config.SpecSource == nil {
+		return nil, ErrSpecNotSet
+	}
+	var validate func(root *yaml.Node) error
+	if config.ValidateSpec {
+		validate = openAPISpecKind.validate
+	}
+	s, err := newLoadedSpec("shared spec", config.Spec, config.SpecSource, validate, config.WatchSpec)
+	if err != nil {
+		return nil, err
+	}
+	return &SharedSpec{spec: s}, nil
+}
+
+// validatedSpec returns the operations of the specification for the validation middlewares, which share them.
+func (s *SharedSpec) validatedSpec() (*validatedSpec, error) {
+	s.once.Do(func() {
+		s.validated, s.err = newValidatedSpec(s.spec)
+	})
+	return s.validated, s.err
+}
+
+// validatedSpecOf returns the validatedSpec of a validation middleware, which is shared if shared is not nil.
+// Otherwise, it is the specification given by spec or source.
+func validatedSpecOf(shared *SharedSpec, spec string, source SpecSource, watch bool) (*validatedSpec, error) {
+	if shared == nil {
+		var err error
+		if shared, err = NewSharedSpec(SharedSpecConfig{Spec: spec, SpecSource: source, WatchSpec: watch}); err != nil {
+			return nil, err
+		}
+	}
+	return shared.validatedSpec()
+}
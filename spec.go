@@ -0,0 +1,207 @@
+package openapidocs
+
+import (
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// specKind is a kind of API specification served by a documentation site.
+type specKind struct {
+	// path is the path under the base path of a documentation site where the specification is served.
+	path string
+	// validate checks that the root node of a specification is a valid document of the kind.
+	validate func(root *yaml.Node) error
+}
+
+var (
+	openAPISpecKind = specKind{
+		path:     "openapi-spec",
+		validate: validateSpecNode,
+	}
+	asyncAPISpecKind = specKind{
+		path:     "asyncapi-spec",
+		validate: validateAsyncAPISpecNode,
+	}
+)
+
+const (
+	mimeApplicationYAML = "application/yaml"
+	mimeTextPlain       = "text/plain; charset=utf-8"
+)
+
+// specFormat is the format of an OpenAPI specification.
+type specFormat int
+
+const (
+	specFormatUnknown specFormat = iota
+	specFormatJSON
+	specFormatYAML
+)
+
+// specDocument is an OpenAPI specification served by a documentation site.
+// It holds the specification in both JSON and YAML, so that clients can get it in the format they prefer.
+type specDocument struct {
+	// format is the format of the specification as it is given.
+	// If it is neither JSON nor YAML, format is specFormatUnknown and the specification is served only as plain text.
+	format specFormat
+	// raw is the specification as it is given. It is used only if format is specFormatUnknown.
+	raw  *specContent
+	json *specContent
+	yaml *specContent
+	// root is the parsed specification, which is shared by the readers of the document and must not be modified.
+	// It is nil if format is specFormatUnknown.
+	root *yaml.Node
+	// modTime is the time when the specification was loaded.
+	modTime time.Time
+}
+
+// specContent is a representation of the specification in a format.
+type specContent struct {
+	contentType string
+	body        []byte
+	etag        string
+	// encoded is the precompressed variants of body.
+	encoded []encodedContent
+}
+
+func newSpecContent(contentType string, body []byte) *specContent {
+	etag := computeETag(body)
+	return &specContent{
+		contentType: contentType,
+		body:        body,
+		etag:        etag,
+		encoded:     precompress(body, etag),
+	}
+}
+
+// newSpecDocument parses spec and converts it into the other format.
+// If validate is not nil, it also validates spec with validate and returns a *SpecValidationError if spec is invalid.
+// Otherwise, a spec that can't be parsed is served as plain text.
+func newSpecDocument(spec string, validate func(root *yaml.Node) error) (*specDocument, error) {
+	doc := &specDocument{
+		modTime: time.Now(),
+	}
+	data := []byte(spec)
+
+	node, format, err := parseSpecNode(data)
+	if err == nil && validate != nil {
+		err = validate(node)
+	}
+	if err == nil {
+		switch format {
+		case specFormatJSON:
+			var y []byte
+			if y, err = marshalNodeYAML(node); err == nil {
+				doc.json = newSpecContent(echo.MIMEApplicationJSON, data)
+				doc.yaml = newSpecContent(mimeApplicationYAML, y)
+			}
+		case specFormatYAML:
+			var j []byte
+			if j, err = marshalNodeJSON(node); err == nil {
+				doc.json = newSpecContent(echo.MIMEApplicationJSON, j)
+				doc.yaml = newSpecContent(mimeApplicationYAML, data)
+			}
+		}
+	}
+	if err != nil {
+		if validate != nil {
+			return nil, newSpecValidationError(err)
+		}
+		doc.raw = newSpecContent(mimeTextPlain, data)
+		return doc, nil
+	}
+
+	doc.format = format
+	doc.root = node
+	return doc, nil
+}
+
+// specEndpoint reports whether relPath points to the endpoint serving the specification at specPath.
+// It returns the format requested by the extension of the path, or specFormatUnknown if the path has no extension.
+func specEndpoint(relPath, specPath string) (specFormat, bool) {
+	switch strings.TrimPrefix(relPath, "/") {
+	case specPath:
+		return specFormatUnknown, true
+	case specPath + ".json":
+		return specFormatJSON, true
+	case specPath + ".yaml", specPath + ".yml":
+		return specFormatYAML, true
+	default:
+		return specFormatUnknown, false
+	}
+}
+
+// serve writes the specification in the format.
+// If format is specFormatUnknown, the format is negotiated with the Accept header, falling back to the original format.
+func (d *specDocument) serve(c echo.Context, format specFormat, cacheControl string) error {
+	content := d.raw
+	if d.format == specFormatUnknown {
+		if format != specFormatUnknown {
+			return echo.ErrNotFound
+		}
+	} else {
+		if format == specFormatUnknown {
+			c.Response().Header().Add(echo.HeaderVary, "Accept")
+			format = negotiateSpecFormat(c.Request().Header.Get(echo.HeaderAccept))
+			if format == specFormatUnknown {
+				format = d.format
+			}
+		}
+		content = d.yaml
+		if format == specFormatJSON {
+			content = d.json
+		}
+	}
+	return content.serve(c, d.modTime, cacheControl)
+}
+
+// serve writes the content, compressed with the encoding negotiated with the Accept-Encoding header.
+func (sc *specContent) serve(c echo.Context, modTime time.Time, cacheControl string) error {
+	body, etag := sc.body, sc.etag
+	if len(sc.encoded) > 0 {
+		h := c.Response().Header()
+		h.Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+		if encoded := negotiateEncoding(c.Request().Header.Get(echo.HeaderAcceptEncoding), sc.encoded); encoded != nil {
+			h.Set(echo.HeaderContentEncoding, encoded.encoding)
+			body, etag = encoded.body, encoded.etag
+		}
+	}
+	return writeCacheable(c, sc.contentType, body, etag, modTime, cacheControl)
+}
+
+// negotiateSpecFormat returns the format with the highest quality in the Accept header.
+// It returns specFormatUnknown if the header doesn't prefer JSON or YAML explicitly.
+func negotiateSpecFormat(accept string) specFormat {
+	format := specFormatUnknown
+	best := 0.0
+	for _, r := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(r))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		var f specFormat
+		switch mediaType {
+		case "application/json":
+			f = specFormatJSON
+		case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+			f = specFormatYAML
+		default:
+			continue
+		}
+		if q > best {
+			format = f
+			best = q
+		}
+	}
+	return format
+}
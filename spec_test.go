@@ -0,0 +1,193 @@
+package openapidocs
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewSpecDocument(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       string
+		wantFormat specFormat
+		wantJSON   string
+		wantYAML   string
+	}{
+		{
+			name:       "YAML",
+			spec:       "openapi: 3.0.0\ninfo:\n  title: Test\n  version: \"1\"\npaths: {}\n",
+			wantFormat: specFormatYAML,
+			wantJSON:   `{"openapi":"3.0.0","info":{"title":"Test","version":"1"},"paths":{}}`,
+			wantYAML:   "openapi: 3.0.0\ninfo:\n  title: Test\n  version: \"1\"\npaths: {}\n",
+		},
+		{
+			name:       "JSON",
+			spec:       `{"openapi": "3.0.0", "info": {"title": "Test", "version": "1"}, "paths": {}}`,
+			wantFormat: specFormatJSON,
+			wantJSON:   `{"openapi": "3.0.0", "info": {"title": "Test", "version": "1"}, "paths": {}}`,
+			wantYAML:   "openapi: 3.0.0\ninfo:\n  title: Test\n  version: \"1\"\npaths: {}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := newSpecDocument(tt.spec, validateSpecNode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.format != tt.wantFormat {
+				t.Errorf("format = %v, want %v", doc.format, tt.wantFormat)
+			}
+			if got := string(doc.json.body); got != tt.wantJSON {
+				t.Errorf("JSON = %s, want %s", got, tt.wantJSON)
+			}
+			if got := string(doc.yaml.body); got != tt.wantYAML {
+				t.Errorf("YAML = %q, want %q", got, tt.wantYAML)
+			}
+			if doc.root == nil {
+				t.Error("root = nil, want the parsed specification")
+			}
+		})
+	}
+}
+
+func TestNewSpecDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		wantLine int
+	}{
+		{name: "syntax error", spec: "openapi: 3.0.0\ninfo: title: a\n", wantLine: 2},
+		{name: "alias cycle", spec: "openapi: 3.0.0\ninfo: &info\n  title: [*info]\n", wantLine: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSpecDocument(tt.spec, validateSpecNode)
+			var validationErr *SpecValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("err = %v, want a *SpecValidationError", err)
+			}
+			if got := validationErr.Errors[0].Line; got != tt.wantLine {
+				t.Errorf("line = %d, want %d", got, tt.wantLine)
+			}
+
+			// Without validation, the specification is served as it is.
+			doc, err := newSpecDocument(tt.spec, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.format != specFormatUnknown || string(doc.raw.body) != tt.spec || doc.root != nil {
+				t.Errorf("document = %+v, want the raw specification", doc)
+			}
+		})
+	}
+}
+
+func TestSpecEndpoint(t *testing.T) {
+	tests := []struct {
+		relPath    string
+		wantFormat specFormat
+		wantOK     bool
+	}{
+		{relPath: "/openapi-spec", wantFormat: specFormatUnknown, wantOK: true},
+		{relPath: "openapi-spec", wantFormat: specFormatUnknown, wantOK: true},
+		{relPath: "/openapi-spec.json", wantFormat: specFormatJSON, wantOK: true},
+		{relPath: "/openapi-spec.yaml", wantFormat: specFormatYAML, wantOK: true},
+		{relPath: "/openapi-spec.yml", wantFormat: specFormatYAML, wantOK: true},
+		{relPath: "/openapi-spec.xml", wantOK: false},
+		{relPath: "/openapi-spec/", wantOK: false},
+		{relPath: "/", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.relPath, func(t *testing.T) {
+			format, ok := specEndpoint(tt.relPath, "openapi-spec")
+			if format != tt.wantFormat || ok != tt.wantOK {
+				t.Errorf("specEndpoint() = %v, %v, want %v, %v", format, ok, tt.wantFormat, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNegotiateSpecFormat(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   specFormat
+	}{
+		{accept: "", want: specFormatUnknown},
+		{accept: "*/*", want: specFormatUnknown},
+		{accept: "text/html", want: specFormatUnknown},
+		{accept: "application/json", want: specFormatJSON},
+		{accept: "application/yaml", want: specFormatYAML},
+		{accept: "application/x-yaml", want: specFormatYAML},
+		{accept: "text/yaml", want: specFormatYAML},
+		{accept: "application/json, application/yaml", want: specFormatJSON},
+		{accept: "application/json;q=0.5, application/yaml", want: specFormatYAML},
+		{accept: "application/yaml;q=0.8, application/json;q=0.9, */*", want: specFormatJSON},
+		{accept: "application/yaml;q=x, application/json;q=0.1", want: specFormatJSON},
+		{accept: "application/json;q=0", want: specFormatUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := negotiateSpecFormat(tt.accept); got != tt.want {
+				t.Errorf("negotiateSpecFormat(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpecDocumentServe(t *testing.T) {
+	yamlDoc, err := newSpecDocument("openapi: 3.0.0\n", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawDoc, err := newSpecDocument("not: a: specification", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		doc             *specDocument
+		format          specFormat
+		accept          string
+		wantStatus      int
+		wantContentType string
+		wantVary        string
+	}{
+		{name: "original format", doc: yamlDoc, wantStatus: http.StatusOK, wantContentType: mimeApplicationYAML, wantVary: "Accept"},
+		{name: "negotiated JSON", doc: yamlDoc, accept: "application/json", wantStatus: http.StatusOK, wantContentType: echo.MIMEApplicationJSON, wantVary: "Accept"},
+		{name: "JSON extension", doc: yamlDoc, format: specFormatJSON, accept: "application/yaml", wantStatus: http.StatusOK, wantContentType: echo.MIMEApplicationJSON},
+		{name: "YAML extension", doc: yamlDoc, format: specFormatYAML, wantStatus: http.StatusOK, wantContentType: mimeApplicationYAML},
+		{name: "raw", doc: rawDoc, accept: "application/json", wantStatus: http.StatusOK, wantContentType: mimeTextPlain},
+		{name: "raw with an extension", doc: rawDoc, format: specFormatJSON, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			err := tt.doc.serve(e.NewContext(req, rec), tt.format, "no-cache")
+			if err != nil {
+				e.HTTPErrorHandler(err, e.NewContext(req, rec))
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get(echo.HeaderContentType); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if got := strings.Join(rec.Header().Values(echo.HeaderVary), ", "); got != tt.wantVary {
+				t.Errorf("Vary = %q, want %q", got, tt.wantVary)
+			}
+		})
+	}
+}
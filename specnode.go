@@ -0,0 +1,432 @@
+package openapidocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"math"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+)
+
+// parseSpecNode parses an OpenAPI specification in JSON or YAML into a YAML node tree.
+// It returns the root mapping node and the detected format.
+// The nodes have the line and column positions in data, also when data is JSON.
+// A syntax error is returned as a *SpecError.
+func parseSpecNode(data []byte) (*yaml.Node, specFormat, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		node, err := parseJSONNode(data)
+		if err != nil {
+			// It may be a YAML flow mapping.
+			if node, yamlErr := parseYAMLNode(data); yamlErr == nil {
+				return node, specFormatYAML, nil
+			}
+			return nil, specFormatUnknown, err
+		}
+		return node, specFormatJSON, nil
+	}
+
+	node, err := parseYAMLNode(data)
+	if err != nil {
+		return nil, specFormatUnknown, err
+	}
+	return node, specFormatYAML, nil
+}
+
+var yamlErrorLinePattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func parseYAMLNode(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		if m := yamlErrorLinePattern.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, &SpecError{Line: line, Message: m[2]}
+		}
+		return nil, &SpecError{Message: err.Error()}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, &SpecError{Message: "the document is empty"}
+	}
+	node := doc.Content[0]
+	if node.Kind != yaml.MappingNode {
+		return nil, &SpecError{Line: node.Line, Column: node.Column, Message: "the document must be a mapping"}
+	}
+	return node, nil
+}
+
+func parseJSONNode(data []byte) (*yaml.Node, error) {
+	r := &jsonNodeReader{
+		data: data,
+		dec:  json.NewDecoder(bytes.NewReader(data)),
+		line: 1,
+		col:  1,
+	}
+	r.dec.UseNumber()
+
+	node, err := r.read()
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			// Offset points just after the invalid character.
+			line, col := r.position(max(int(syntaxErr.Offset)-1, 0))
+			return nil, &SpecError{Line: line, Column: col, Message: syntaxErr.Error()}
+		}
+		var specErr *SpecError
+		if errors.As(err, &specErr) {
+			return nil, err
+		}
+		line, col := r.position(int(r.dec.InputOffset()))
+		return nil, &SpecError{Line: line, Column: col, Message: err.Error()}
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, &SpecError{Line: node.Line, Column: node.Column, Message: "the document must be an object"}
+	}
+	line, col := r.nextPosition()
+	if _, err := r.dec.Token(); err == nil {
+		return nil, &SpecError{Line: line, Column: col, Message: "unexpected data after the top-level object"}
+	}
+	return node, nil
+}
+
+// jsonNodeReader reads JSON into a YAML node tree, recording the positions of the values.
+type jsonNodeReader struct {
+	data []byte
+	dec  *json.Decoder
+	// offset, line and col are the last computed position. Positions are computed incrementally because
+	// the decoder only moves forward.
+	offset int
+	line   int
+	col    int
+}
+
+// position returns the line and the column of the byte offset in data. The column counts characters.
+func (r *jsonNodeReader) position(offset int) (int, int) {
+	if offset > len(r.data) {
+		offset = len(r.data)
+	}
+	if offset < r.offset {
+		r.offset, r.line, r.col = 0, 1, 1
+	}
+	for r.offset < offset {
+		b := r.data[r.offset]
+		if b == '\n' {
+			r.line++
+			r.col = 1
+		} else if utf8.RuneStart(b) {
+			r.col++
+		}
+		r.offset++
+	}
+	return r.line, r.col
+}
+
+// nextPosition returns the position of the next token.
+func (r *jsonNodeReader) nextPosition() (int, int) {
+	offset := int(r.dec.InputOffset())
+	for offset < len(r.data) && bytes.IndexByte([]byte(" \t\r\n,:"), r.data[offset]) >= 0 {
+		offset++
+	}
+	return r.position(offset)
+}
+
+func (r *jsonNodeReader) read() (*yaml.Node, error) {
+	line, col := r.nextPosition()
+	tok, err := r.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &yaml.Node{Line: line, Column: col}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+			for r.dec.More() {
+				keyLine, keyCol := r.nextPosition()
+				keyTok, err := r.dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := keyTok.(string)
+				if !ok {
+					return nil, &SpecError{Line: keyLine, Column: keyCol, Message: fmt.Sprintf("unexpected object key %v", keyTok)}
+				}
+				value, err := r.read()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key, Line: keyLine, Column: keyCol}, value)
+			}
+		case '[':
+			node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+			for r.dec.More() {
+				item, err := r.read()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, item)
+			}
+		default:
+			return nil, &SpecError{Line: line, Column: col, Message: fmt.Sprintf("unexpected %v", t)}
+		}
+		// consume the closing delimiter
+		if _, err := r.dec.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!str", t
+	case json.Number:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!int", t.String()
+		if bytes.ContainsAny([]byte(t), ".eE") {
+			node.Tag = "!!float"
+		}
+	case bool:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!bool", strconv.FormatBool(t)
+	case nil:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!null", "null"
+	}
+	return node, nil
+}
+
+// marshalNodeYAML encodes a node tree into YAML.
+func marshalNodeYAML(node *yaml.Node) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// maxAliasedNodes is the maximum number of nodes written through aliases and merge keys when a node tree is encoded
+// into JSON, so that a small document with nested aliases can't expand into a huge one.
+const maxAliasedNodes = 1 << 20
+
+// marshalNodeJSON encodes a node tree into JSON, keeping the order of the mapping keys.
+// Aliases are expanded. An error is returned if an alias refers to the node that contains it,
+// or the aliases expand into more than maxAliasedNodes nodes.
+func marshalNodeJSON(node *yaml.Node) ([]byte, error) {
+	w := &nodeJSONWriter{
+		buf:       new(bytes.Buffer),
+		expanding: map[*yaml.Node]int{},
+	}
+	if err := w.write(node); err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// nodeJSONWriter writes a node tree in JSON.
+type nodeJSONWriter struct {
+	buf *bytes.Buffer
+	// expanding counts the nodes being written through aliases or merge keys.
+	expanding map[*yaml.Node]int
+	// aliased is the number of nodes written through aliases or merge keys.
+	aliased int
+}
+
+func (w *nodeJSONWriter) write(node *yaml.Node) error {
+	if len(w.expanding) > 0 {
+		if w.aliased++; w.aliased > maxAliasedNodes {
+			return &SpecError{Line: node.Line, Column: node.Column, Message: fmt.Sprintf("the aliases expand into more than %d nodes", maxAliasedNodes)}
+		}
+	}
+
+	buf := w.buf
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return w.write(node.Content[0])
+	case yaml.AliasNode:
+		if w.expanding[node.Alias] > 0 {
+			return &SpecError{Line: node.Line, Column: node.Column, Message: "the alias refers to the node that contains it"}
+		}
+		return w.expand(node.Alias)
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := w.write(item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case yaml.MappingNode:
+		keys, values := yamlMappingEntries(node)
+		// The values that are not in the content of the node are merged from other mappings.
+		own := make(map[*yaml.Node]bool, len(node.Content)/2)
+		for i := 1; i < len(node.Content); i += 2 {
+			own[node.Content[i]] = true
+		}
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			k, _ := json.Marshal(key)
+			buf.Write(k)
+			buf.WriteByte(':')
+			write := w.write
+			if !own[values[i]] {
+				write = w.expand
+			}
+			if err := write(values[i]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case yaml.ScalarNode:
+		v, err := scalarNodeValue(node)
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		return nil
+	default:
+		return &SpecError{Line: node.Line, Column: node.Column, Message: "unsupported YAML node"}
+	}
+}
+
+// expand writes the node an alias or a merge key refers to.
+func (w *nodeJSONWriter) expand(node *yaml.Node) error {
+	w.expanding[node]++
+	defer func() {
+		if w.expanding[node]--; w.expanding[node] == 0 {
+			delete(w.expanding, node)
+		}
+	}()
+	return w.write(node)
+}
+
+// scalarNodeValue returns the value of a scalar node as a JSON compatible value.
+// Values that JSON can't represent, such as timestamps and infinity, are returned as strings.
+func scalarNodeValue(node *yaml.Node) (any, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return nil, err
+		}
+		return b, nil
+	case "!!int":
+		var i int64
+		if err := node.Decode(&i); err == nil {
+			return i, nil
+		}
+		fallthrough
+	case "!!float":
+		var f float64
+		if err := node.Decode(&f); err != nil {
+			return nil, err
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return node.Value, nil
+		}
+		return f, nil
+	default:
+		return node.Value, nil
+	}
+}
+
+// yamlMappingEntries returns the keys and the values of a mapping node in order.
+// Merge keys (`<<`) are expanded. Explicit keys take precedence over merged keys,
+// and the keys of a mapping merged earlier take precedence over the ones of a mapping merged later.
+func yamlMappingEntries(node *yaml.Node) ([]string, []*yaml.Node) {
+	return mergedMappingEntries(node, map[*yaml.Node]bool{})
+}
+
+// mergedMappingEntries returns the entries of a mapping node like yamlMappingEntries.
+// The mappings in merged have been merged already, and are left out because they add no keys.
+// It also stops a mapping that merges itself from recursing forever.
+func mergedMappingEntries(node *yaml.Node, merged map[*yaml.Node]bool) ([]string, []*yaml.Node) {
+	merged[node] = true
+	var keys []string
+	var values []*yaml.Node
+	index := map[string]int{}
+	set := func(key string, value *yaml.Node, override bool) {
+		if i, ok := index[key]; ok {
+			if override {
+				values[i] = value
+			}
+			return
+		}
+		index[key] = len(keys)
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Kind == yaml.AliasNode {
+			key = key.Alias
+		}
+		if key.ShortTag() != "!!merge" {
+			set(key.Value, value, true)
+			continue
+		}
+
+		if value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, m := range sources {
+			if m.Kind == yaml.AliasNode {
+				m = m.Alias
+			}
+			if m.Kind != yaml.MappingNode || merged[m] {
+				continue
+			}
+			mk, mv := mergedMappingEntries(m, merged)
+			for j := range mk {
+				set(mk[j], mv[j], false)
+			}
+		}
+	}
+	return keys, values
+}
+
+// mappingValue returns the value of the key in a mapping node, or nil if the key doesn't exist or node is not a mapping.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	keys, values := yamlMappingEntries(node)
+	for i, k := range keys {
+		if k == key {
+			return resolveAlias(values[i])
+		}
+	}
+	return nil
+}
+
+// resolveAlias returns the node an alias node refers to.
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
@@ -0,0 +1,248 @@
+package openapidocs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMarshalNodeJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{
+			name: "keys in order",
+			yaml: "b: 1\na: 2\nc: 3\n",
+			want: `{"b":1,"a":2,"c":3}`,
+		},
+		{
+			name: "scalars",
+			yaml: "int: 1\nfloat: 1.5\nbool: true\nnull: ~\nstr: \"1\"\nhex: 0x1F\ntime: 2001-12-14t21:59:43.10-05:00\ninf: .inf\n",
+			want: `{"int":1,"float":1.5,"bool":true,"null":null,"str":"1","hex":31,"time":"2001-12-14t21:59:43.10-05:00","inf":".inf"}`,
+		},
+		{
+			name: "big int",
+			yaml: "n: 18446744073709551616\n",
+			want: `{"n":18446744073709552000}`,
+		},
+		{
+			name: "sequences",
+			yaml: "a: [1, [2, 3], {b: c}]\n",
+			want: `{"a":[1,[2,3],{"b":"c"}]}`,
+		},
+		{
+			name: "aliases",
+			yaml: "a: &x {b: 1}\nc: *x\nd: [*x, *x]\n",
+			want: `{"a":{"b":1},"c":{"b":1},"d":[{"b":1},{"b":1}]}`,
+		},
+		{
+			name: "merge keys",
+			yaml: "base: &base {a: 1, b: 2}\nderived:\n  <<: *base\n  b: 3\n  c: 4\n",
+			want: `{"base":{"a":1,"b":2},"derived":{"a":1,"b":3,"c":4}}`,
+		},
+		{
+			name: "merge key after the explicit keys",
+			yaml: "base: &base {a: 1, b: 2}\nderived:\n  b: 3\n  <<: *base\n",
+			want: `{"base":{"a":1,"b":2},"derived":{"b":3,"a":1}}`,
+		},
+		{
+			name: "earlier merged mapping takes precedence",
+			yaml: "x: &x {a: 1}\ny: &y {a: 2, b: 2}\nz:\n  <<: [*x, *y]\n",
+			want: `{"x":{"a":1},"y":{"a":2,"b":2},"z":{"a":1,"b":2}}`,
+		},
+		{
+			name: "mapping merging itself",
+			yaml: "a: &a\n  <<: *a\n  x: 1\n",
+			want: `{"a":{"x":1}}`,
+		},
+		{
+			name: "duplicate merge",
+			yaml: "x: &x {a: 1}\ny: &y\n  <<: *x\nz:\n  <<: [*y, *x]\n",
+			want: `{"x":{"a":1},"y":{"a":1},"z":{"a":1}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, _, err := parseSpecNode([]byte(tt.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := marshalNodeJSON(node)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("marshalNodeJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalNodeJSONAliasErrors(t *testing.T) {
+	// laughs nests aliases 10 levels deep, which expand into 10^10 nodes.
+	var laughs strings.Builder
+	laughs.WriteString("l0: &l0 [lol]\n")
+	for i := 1; i <= 10; i++ {
+		items := strings.TrimSuffix(strings.Repeat(fmt.Sprintf("*l%d, ", i-1), 10), ", ")
+		fmt.Fprintf(&laughs, "l%d: &l%d [%s]\n", i, i, items)
+	}
+
+	tests := []struct {
+		name        string
+		yaml        string
+		wantMessage string
+		wantLine    int
+	}{
+		{
+			name:        "sequence containing itself",
+			yaml:        "a: &a [*a]\n",
+			wantMessage: "the alias refers to the node that contains it",
+			wantLine:    1,
+		},
+		{
+			name:        "mapping containing itself",
+			yaml:        "a:\n  b: &b\n    c: *b\n",
+			wantMessage: "the alias refers to the node that contains it",
+			wantLine:    3,
+		},
+		{
+			name:        "merged mapping containing the merging one",
+			yaml:        "a: &a\n  <<: {b: *a}\n",
+			wantMessage: "the alias refers to the node that contains it",
+			wantLine:    2,
+		},
+		{
+			name:        "nested aliases",
+			yaml:        laughs.String(),
+			wantMessage: "the aliases expand into more than",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, _, err := parseSpecNode([]byte(tt.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = marshalNodeJSON(node)
+			var specErr *SpecError
+			if !errors.As(err, &specErr) {
+				t.Fatalf("err = %v, want a *SpecError", err)
+			}
+			if !strings.Contains(specErr.Message, tt.wantMessage) {
+				t.Errorf("message = %q, want %q", specErr.Message, tt.wantMessage)
+			}
+			if tt.wantLine != 0 && specErr.Line != tt.wantLine {
+				t.Errorf("line = %d, want %d", specErr.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestParseSpecNode(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantFormat specFormat
+		wantErr    *SpecError
+	}{
+		{name: "JSON", data: `{"openapi": "3.0.0"}`, wantFormat: specFormatJSON},
+		{name: "YAML", data: "openapi: 3.0.0\n", wantFormat: specFormatYAML},
+		{name: "YAML flow mapping", data: "{openapi: 3.0.0}", wantFormat: specFormatYAML},
+		{
+			name:    "JSON syntax error",
+			data:    "{\n  \"openapi\": \"3.0.0\",\n  \"tags\": [1, 2}\n}",
+			wantErr: &SpecError{Line: 3, Column: 16, Message: "invalid character '}' after array element"},
+		},
+		{
+			name:    "JSON syntax error after multibyte characters",
+			data:    "{\"title\": \"日本語\" \"x\"}",
+			wantErr: &SpecError{Line: 1, Column: 17, Message: "invalid character '\"' after object key:value pair"},
+		},
+		{
+			name:    "YAML syntax error",
+			data:    "openapi: 3.0.0\ninfo: title: a\n",
+			wantErr: &SpecError{Line: 2, Message: "mapping values are not allowed in this context"},
+		},
+		{
+			name:    "YAML not a mapping",
+			data:    "\n- openapi\n",
+			wantErr: &SpecError{Line: 2, Column: 1, Message: "the document must be a mapping"},
+		},
+		{
+			name:    "empty",
+			data:    "",
+			wantErr: &SpecError{Message: "the document is empty"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, format, err := parseSpecNode([]byte(tt.data))
+			if tt.wantErr != nil {
+				var specErr *SpecError
+				if !errors.As(err, &specErr) {
+					t.Fatalf("err = %v, want a *SpecError", err)
+				}
+				if *specErr != *tt.wantErr {
+					t.Errorf("err = %+v, want %+v", *specErr, *tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != tt.wantFormat {
+				t.Errorf("format = %v, want %v", format, tt.wantFormat)
+			}
+			if got := mappingValue(node, "openapi"); got == nil || got.Value != "3.0.0" {
+				t.Errorf("openapi = %v, want 3.0.0", got)
+			}
+		})
+	}
+}
+
+func TestParseJSONNodeDataAfterObject(t *testing.T) {
+	// YAML accepts the data after a flow mapping, so parseSpecNode doesn't report it.
+	_, err := parseJSONNode([]byte("{\"openapi\": \"3.0.0\"}\n  {}"))
+	var specErr *SpecError
+	if !errors.As(err, &specErr) {
+		t.Fatalf("err = %v, want a *SpecError", err)
+	}
+	want := SpecError{Line: 2, Column: 3, Message: "unexpected data after the top-level object"}
+	if *specErr != want {
+		t.Errorf("err = %+v, want %+v", *specErr, want)
+	}
+}
+
+func TestParseJSONNodePositions(t *testing.T) {
+	data := "{\n  \"info\": {\n    \"title\": \"日本語\",\n    \"version\": 1\n  }\n}"
+	root, _, err := parseSpecNode([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := mappingValue(root, "info")
+
+	tests := []struct {
+		name       string
+		key        string
+		wantLine   int
+		wantColumn int
+		wantTag    string
+	}{
+		{name: "string", key: "title", wantLine: 3, wantColumn: 14, wantTag: "!!str"},
+		{name: "number", key: "version", wantLine: 4, wantColumn: 16, wantTag: "!!int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := mappingValue(info, tt.key)
+			if node.Line != tt.wantLine || node.Column != tt.wantColumn {
+				t.Errorf("position = %d:%d, want %d:%d", node.Line, node.Column, tt.wantLine, tt.wantColumn)
+			}
+			if node.Tag != tt.wantTag {
+				t.Errorf("tag = %s, want %s", node.Tag, tt.wantTag)
+			}
+		})
+	}
+}
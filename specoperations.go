@@ -0,0 +1,383 @@
+package openapidocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"mime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// specOperation is an operation of an OpenAPI specification that requests and responses are validated against.
+type specOperation struct {
+	method string
+	// path is the path template of the operation including the base path, such as `/api/users/{id}`.
+	path string
+	// pathParams is the names of the path parameters in the order of the path template.
+	pathParams []string
+	parameters []*specParameter
+	// requestBody is the request body object. The body parameter of Swagger 2.0 is converted into it.
+	requestBody map[string]any
+	// responses is the responses object. The schemas of the responses of Swagger 2.0 are converted into `content`.
+	responses map[string]any
+}
+
+// specParameter is a parameter of an operation.
+type specParameter struct {
+	name string
+	// in is the location of the parameter, which is `path`, `query`, `header` or `cookie`.
+	in       string
+	required bool
+	schema   any
+	// explode reports whether the values of an array are given as separate parameters, such as `?id=1&id=2`.
+	explode bool
+	// separator is the separator of the values of an array that are not exploded.
+	separator string
+}
+
+// specOperations is the operations of a specification.
+type specOperations struct {
+	// etag identifies the version of the specification the operations are built from.
+	etag       string
+	validator  *schemaValidator
+	operations map[string]*specOperation
+}
+
+// newSpecOperations builds the operations of the specification in JSON.
+func newSpecOperations(spec []byte, etag string) (*specOperations, error) {
+	root, _, err := parseSpecNode(spec)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(spec))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	rootMap, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("the specification must be an object")
+	}
+
+	ops := &specOperations{
+		etag:       etag,
+		validator:  newSchemaValidator(doc),
+		operations: map[string]*specOperation{},
+	}
+	_, swagger := rootMap["swagger"]
+	prefix := driftBasePath(root)
+	paths, _ := rootMap["paths"].(map[string]any)
+	for p, item := range paths {
+		itemMap := ops.validator.deref(item)
+		if itemMap == nil {
+			continue
+		}
+		for field, opNode := range itemMap {
+			method, ok := driftMethods[field]
+			if !ok {
+				continue
+			}
+			opMap, ok := opNode.(map[string]any)
+			if !ok {
+				continue
+			}
+			op := &specOperation{
+				method:     method,
+				path:       prefix + p,
+				pathParams: specPathParams(p),
+			}
+			if swagger {
+				ops.buildSwaggerOperation(op, rootMap, itemMap, opMap)
+			} else {
+				ops.buildOperation(op, itemMap, opMap)
+			}
+			ops.operations[driftKey(method, op.path)] = op
+		}
+	}
+	return ops, nil
+}
+
+// buildOperation builds op from an operation object of OpenAPI 3.
+func (ops *specOperations) buildOperation(op *specOperation, item, opMap map[string]any) {
+	for _, param := range ops.parameters(item, opMap) {
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		p := &specParameter{
+			name:   name,
+			in:     in,
+			schema: param["schema"],
+		}
+		p.required, _ = param["required"].(bool)
+
+		style, _ := param["style"].(string)
+		if style == "" {
+			style = "simple"
+			if in == "query" || in == "cookie" {
+				style = "form"
+			}
+		}
+		explode, ok := param["explode"].(bool)
+		if !ok {
+			explode = style == "form"
+		}
+		p.explode = explode
+		switch style {
+		case "spaceDelimited":
+			p.separator = " "
+		case "pipeDelimited":
+			p.separator = "|"
+		default:
+			p.separator = ","
+		}
+		op.parameters = append(op.parameters, p)
+	}
+	op.requestBody = ops.validator.deref(opMap["requestBody"])
+
+	if responses, ok := opMap["responses"].(map[string]any); ok {
+		op.responses = map[string]any{}
+		for status, res := range responses {
+			op.responses[status] = ops.validator.deref(res)
+		}
+	}
+}
+
+// buildSwaggerOperation builds op from an operation object of Swagger 2.0.
+// The body parameter and the schemas of the responses are converted into the objects of OpenAPI 3.
+func (ops *specOperations) buildSwaggerOperation(op *specOperation, root, item, opMap map[string]any) {
+	consumes := swaggerMediaTypes(opMap, root, "consumes")
+	produces := swaggerMediaTypes(opMap, root, "produces")
+
+	for _, param := range ops.parameters(item, opMap) {
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		required, _ := param["required"].(bool)
+		switch in {
+		case "body":
+			content := map[string]any{}
+			for _, mediaType := range consumes {
+				content[mediaType] = map[string]any{"schema": param["schema"]}
+			}
+			op.requestBody = map[string]any{"required": required, "content": content}
+			continue
+		case "formData":
+			// Form data is not validated.
+			continue
+		}
+
+		// The parameter has the schema keywords by itself.
+		p := &specParameter{
+			name:      name,
+			in:        in,
+			required:  required,
+			schema:    param,
+			separator: ",",
+		}
+		switch format, _ := param["collectionFormat"].(string); format {
+		case "multi":
+			p.explode = true
+		case "ssv":
+			p.separator = " "
+		case "tsv":
+			p.separator = "\t"
+		case "pipes":
+			p.separator = "|"
+		}
+		op.parameters = append(op.parameters, p)
+	}
+
+	if responses, ok := opMap["responses"].(map[string]any); ok {
+		op.responses = map[string]any{}
+		for status, res := range responses {
+			resMap := ops.validator.deref(res)
+			if resMap == nil {
+				continue
+			}
+			converted := map[string]any{}
+			if schema, ok := resMap["schema"]; ok {
+				content := map[string]any{}
+				for _, mediaType := range produces {
+					content[mediaType] = map[string]any{"schema": schema}
+				}
+				converted["content"] = content
+			}
+			if headers, ok := resMap["headers"]; ok {
+				converted["headers"] = headers
+			}
+			op.responses[status] = converted
+		}
+	}
+}
+
+// parameters returns the parameters of the operation including the parameters of the path item,
+// which are overridden by the parameters of the operation with the same name and location.
+func (ops *specOperations) parameters(item, opMap map[string]any) []map[string]any {
+	var params []map[string]any
+	index := map[string]int{}
+	for _, list := range []any{item["parameters"], opMap["parameters"]} {
+		values, _ := list.([]any)
+		for _, value := range values {
+			param := ops.validator.deref(value)
+			if param == nil {
+				continue
+			}
+			name, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			key := in + " " + name
+			if in == "header" {
+				key = in + " " + strings.ToLower(name)
+			}
+			if i, ok := index[key]; ok {
+				params[i] = param
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// swaggerMediaTypes returns the media types of `consumes` or `produces` of a Swagger 2.0 operation,
+// which default to the ones of the document and `application/json`.
+func swaggerMediaTypes(opMap, root map[string]any, field string) []string {
+	for _, m := range []map[string]any{opMap, root} {
+		if values, ok := m[field].([]any); ok && len(values) > 0 {
+			var types []string
+			for _, v := range values {
+				if s, ok := v.(string); ok {
+					types = append(types, s)
+				}
+			}
+			return types
+		}
+	}
+	return []string{echo.MIMEApplicationJSON}
+}
+
+// specPathParams returns the names of the parameters in a path template, such as `id` for `/users/{id}`.
+func specPathParams(p string) []string {
+	var names []string
+	for _, m := range driftParamPattern.FindAllString(p, -1) {
+		names = append(names, strings.TrimSuffix(strings.TrimPrefix(m, "{"), "}"))
+	}
+	return names
+}
+
+// find returns the operation of the echo route that handles the request, or nil if the specification doesn't have it.
+func (ops *specOperations) find(c echo.Context) *specOperation {
+	routePath := c.Path()
+	if routePath == "" {
+		return nil
+	}
+	p, _ := routeSpecPath(routePath)
+	return ops.operations[driftKey(c.Request().Method, p)]
+}
+
+// pathParamValues returns the values of the path parameters of op by their names in the specification.
+// The path parameters of the echo route are matched by their positions, because their names may differ.
+func (op *specOperation) pathParamValues(c echo.Context) map[string]string {
+	values := c.ParamValues()
+	params := make(map[string]string, len(op.pathParams))
+	for i, name := range op.pathParams {
+		if i < len(values) {
+			params[name] = values[i]
+		}
+	}
+	return params
+}
+
+// findMediaType returns the media type object in content that matches the content type, and its name.
+// The most specific one of an exact match, `type/*` and `*/*` is returned.
+func findMediaType(content map[string]any, contentType string) (map[string]any, string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	main, _, _ := strings.Cut(mediaType, "/")
+	var candidates []string
+	for name := range content {
+		candidates = append(candidates, name)
+	}
+	for _, want := range []string{mediaType, main + "/*", "*/*"} {
+		for _, name := range candidates {
+			parsed, _, err := mime.ParseMediaType(name)
+			if err != nil {
+				parsed = strings.ToLower(name)
+			}
+			if parsed == want {
+				m, _ := content[name].(map[string]any)
+				return m, name, true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// isJSONMediaType reports whether the media type is JSON, such as `application/json` or `application/problem+json`.
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == echo.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+// validatedSpec is the specification of a validation middleware. It rebuilds the operations when the specification
+// is reloaded from its source.
+type validatedSpec struct {
+	spec *loadedSpec
+	ops  atomic.Pointer[specOperations]
+	// mu serializes the rebuilds. failed is the etag of the specification whose operations failed to be built,
+	// so that the error is logged only once.
+	mu     sync.Mutex
+	failed string
+}
+
+// newValidatedSpec returns the validatedSpec of the specification s.
+func newValidatedSpec(s *loadedSpec) (*validatedSpec, error) {
+	data := s.specJSON()
+	if data == nil {
+		return nil, errSpecNotParsed
+	}
+	ops, err := newSpecOperations(data, s.etag())
+	if err != nil {
+		return nil, err
+	}
+	v := &validatedSpec{spec: s}
+	v.ops.Store(ops)
+	return v, nil
+}
+
+// operations returns the operations of the current specification, reloading it if it is watched.
+// If the operations of a reloaded specification can't be built, the error is logged and the last ones are returned.
+func (v *validatedSpec) operations(c echo.Context) *specOperations {
+	reloadSpec(c, v.spec)
+	etag := v.spec.etag()
+	if ops := v.ops.Load(); ops.etag == etag {
+		return ops
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if ops := v.ops.Load(); ops.etag == etag || v.failed == etag {
+		return ops
+	}
+	data := v.spec.specJSON()
+	if data == nil {
+		v.failed = etag
+		c.Logger().Errorf("openapidocs: failed to reload the specification: %v", errSpecNotParsed)
+		return v.ops.Load()
+	}
+	ops, err := newSpecOperations(data, etag)
+	if err != nil {
+		v.failed = etag
+		c.Logger().Errorf("openapidocs: failed to reload the specification: %v", err)
+		return v.ops.Load()
+	}
+	v.ops.Store(ops)
+	return ops
+}
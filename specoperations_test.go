@@ -0,0 +1,190 @@
+package openapidocs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewSpecOperations(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		key  string
+		want *specOperation
+	}{
+		{
+			name: "server path and overridden parameters",
+			spec: `{
+  "openapi": "3.0.3",
+  "servers": [{"url": "https://example.com/api/"}],
+  "paths": {
+    "/users/{id}": {
+      "parameters": [
+        {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}},
+        {"name": "X-Token", "in": "header", "schema": {"type": "string"}},
+        {"$ref": "#/components/parameters/Fields"}
+      ],
+      "get": {
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}},
+          {"name": "x-token", "in": "header", "required": true, "schema": {"type": "string"}},
+          {"name": "sort", "in": "query", "style": "spaceDelimited", "schema": {"type": "array"}}
+        ],
+        "responses": {"200": {"$ref": "#/components/responses/User"}}
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "Fields": {"name": "fields", "in": "query", "explode": false, "schema": {"type": "array"}}
+    },
+    "responses": {
+      "User": {"description": "The user.", "content": {"application/json": {"schema": {"type": "object"}}}}
+    }
+  }
+}`,
+			key: "GET /api/users/{}",
+			want: &specOperation{
+				method:     "GET",
+				path:       "/api/users/{id}",
+				pathParams: []string{"id"},
+				parameters: []*specParameter{
+					{name: "id", in: "path", required: true, schema: map[string]any{"type": "integer"}, separator: ","},
+					{name: "x-token", in: "header", required: true, schema: map[string]any{"type": "string"}, separator: ","},
+					{name: "fields", in: "query", schema: map[string]any{"type": "array"}, separator: ","},
+					{name: "sort", in: "query", schema: map[string]any{"type": "array"}, separator: " "},
+				},
+				responses: map[string]any{
+					"200": map[string]any{
+						"description": "The user.",
+						"content":     map[string]any{"application/json": map[string]any{"schema": map[string]any{"type": "object"}}},
+					},
+				},
+			},
+		},
+		{
+			name: "templated server",
+			spec: `{
+  "openapi": "3.1.0",
+  "servers": [{"url": "https://{host}/api"}],
+  "paths": {"/users": {"post": {"requestBody": {"$ref": "#/components/requestBodies/User"}}}},
+  "components": {"requestBodies": {"User": {"content": {"application/json": {}}}}}
+}`,
+			key: "POST /users",
+			want: &specOperation{
+				method:      "POST",
+				path:        "/users",
+				requestBody: map[string]any{"content": map[string]any{"application/json": map[string]any{}}},
+			},
+		},
+		{
+			name: "Swagger 2.0",
+			spec: `{
+  "swagger": "2.0",
+  "basePath": "/v1",
+  "produces": ["application/xml"],
+  "paths": {
+    "/items/{id}": {
+      "put": {
+        "consumes": ["application/json", "text/plain"],
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "integer"},
+          {"name": "tags", "in": "query", "type": "array", "collectionFormat": "tsv"},
+          {"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Item"}},
+          {"name": "file", "in": "formData", "type": "file"}
+        ],
+        "responses": {
+          "200": {"description": "The item.", "schema": {"$ref": "#/definitions/Item"}, "headers": {"ETag": {"type": "string"}}},
+          "204": {"description": "No item."}
+        }
+      }
+    }
+  },
+  "definitions": {"Item": {"type": "object"}}
+}`,
+			key: "PUT /v1/items/{}",
+			want: &specOperation{
+				method:     "PUT",
+				path:       "/v1/items/{id}",
+				pathParams: []string{"id"},
+				parameters: []*specParameter{
+					{
+						name:      "id",
+						in:        "path",
+						required:  true,
+						schema:    map[string]any{"name": "id", "in": "path", "required": true, "type": "integer"},
+						separator: ",",
+					},
+					{
+						name:      "tags",
+						in:        "query",
+						schema:    map[string]any{"name": "tags", "in": "query", "type": "array", "collectionFormat": "tsv"},
+						separator: "\t",
+					},
+				},
+				requestBody: map[string]any{
+					"required": true,
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": map[string]any{"$ref": "#/definitions/Item"}},
+						"text/plain":       map[string]any{"schema": map[string]any{"$ref": "#/definitions/Item"}},
+					},
+				},
+				responses: map[string]any{
+					"200": map[string]any{
+						"content": map[string]any{"application/xml": map[string]any{"schema": map[string]any{"$ref": "#/definitions/Item"}}},
+						"headers": map[string]any{"ETag": map[string]any{"type": "string"}},
+					},
+					"204": map[string]any{},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := newSpecOperations([]byte(tt.spec), "etag")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ops.operations) != 1 {
+				t.Errorf("operations = %v, want only %s", ops.operations, tt.key)
+			}
+			got := ops.operations[tt.key]
+			if got == nil {
+				t.Fatalf("the operation %s is not found in %v", tt.key, ops.operations)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("operation = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindMediaType(t *testing.T) {
+	content := map[string]any{
+		"application/json": map[string]any{"exact": true},
+		"text/*":           map[string]any{},
+		"*/*":              map[string]any{},
+	}
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{contentType: "application/json; charset=utf-8", want: "application/json"},
+		{contentType: "Application/JSON", want: "application/json"},
+		{contentType: "text/plain", want: "text/*"},
+		{contentType: "image/png", want: "*/*"},
+		{contentType: "", want: "*/*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			_, name, ok := findMediaType(content, tt.contentType)
+			if name != tt.want || !ok {
+				t.Errorf("findMediaType(%q) = %q, %v, want %q, true", tt.contentType, name, ok, tt.want)
+			}
+		})
+	}
+
+	if _, _, ok := findMediaType(map[string]any{"application/json": map[string]any{}}, "text/plain"); ok {
+		t.Error("findMediaType() matches a content type that isn't in the content")
+	}
+}
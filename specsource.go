@@ -0,0 +1,78 @@
+package openapidocs
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SpecSource is a source of a specification that may change while the server is running, such as a file.
+// Implement it to load the specification from somewhere else.
+type SpecSource interface {
+	// Version returns a value that changes when the specification changes, such as the modification time of a file.
+	// It is called while serving requests if the specification is watched, so it should be cheap.
+	Version() (string, error)
+	// Load returns the specification.
+	Load() ([]byte, error)
+}
+
+// specWatchInterval is the minimum interval between the checks of a watched SpecSource.
+const specWatchInterval = time.Second
+
+// fsSpecSource is a SpecSource that reads a file from an fs.FS.
+type fsSpecSource struct {
+	fsys fs.FS
+	name string
+}
+
+// SpecFS returns a SpecSource that reads the file name from fsys.
+// The version of the specification is the modification time and the size of the file.
+func SpecFS(fsys fs.FS, name string) SpecSource {
+	return &fsSpecSource{
+		fsys: fsys,
+		name: name,
+	}
+}
+
+// SpecFile returns a SpecSource that reads the file at path, such as `openapi.yaml`.
+// The version of the specification is the modification time and the size of the file.
+func SpecFile(path string) SpecSource {
+	return SpecFS(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+func (s *fsSpecSource) Version() (string, error) {
+	fi, err := fs.Stat(s.fsys, s.name)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(fi.ModTime().UnixNano(), 10) + "-" + strconv.FormatInt(fi.Size(), 10), nil
+}
+
+func (s *fsSpecSource) Load() ([]byte, error) {
+	return fs.ReadFile(s.fsys, s.name)
+}
+
+// errSpecNotParsed is returned when a reloaded specification can't be parsed while the current one can.
+var errSpecNotParsed = errors.New("the specification is neither JSON nor YAML")
+
+// loadSpecSource loads the specification from source and returns the document and the version of the source.
+func loadSpecSource(source SpecSource, validate func(root *yaml.Node) error) (*specDocument, string, error) {
+	version, err := source.Version()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load the specification: %w", err)
+	}
+	data, err := source.Load()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load the specification: %w", err)
+	}
+	doc, err := newSpecDocument(string(data), validate)
+	if err != nil {
+		return nil, "", err
+	}
+	return doc, version, nil
+}
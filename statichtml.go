@@ -0,0 +1,314 @@
+package openapidocs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	htmltemplate "html/template"
+	"io"
+)
+
+// StaticHTMLConfig is the configuration for StaticHTMLDocumentsHandler to generate the OpenAPI documentation as static HTML.
+// The documentation is rendered on the server from Spec, so it works without JavaScript in the browser.
+// It supports OpenAPI 3.x and Swagger 2.0 documents.
+type StaticHTMLConfig struct {
+	// Spec is the OpenAPI specification. It is required because the specification is rendered on the server.
+	// Each specification of Specs must have Spec, SpecSource or SharedSpec for the same reason.
+	Spec string
+	// SiteConfig is the configuration of the documentation site that is common to all renderers,
+	// such as the source of the specification and HTTP caching.
+	SiteConfig
+	// Title is the title of the page.
+	Title string
+	// Template is a template string for rendering the page with html/template.
+	Template string
+}
+
+type staticHTMLTemplateParams struct {
+	StaticHTMLConfig
+	BasePath      string
+	Specs         []PageSpec
+	LiveReloadUrl string
+	SpecUrl       string
+	Document      *staticHTMLSpec
+}
+
+var DefaultStaticHTMLConfig = StaticHTMLConfig{
+	Spec:       "",
+	SiteConfig: DefaultSiteConfig,
+	Title:      "API documentation",
+	Template:   defaultStaticHTMLTemplate,
+}
+
+const defaultStaticHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Title }}</title>
+  <style>
+    body { margin: 0; font-family: system-ui, -apple-system, "Segoe UI", sans-serif; line-height: 1.5; color: #1f2328; }
+    header, nav, main { max-width: 64rem; margin: 0 auto; padding: 0 1rem; }
+    a { color: #0550ae; }
+    code, pre { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; }
+    pre { background: #f6f8fa; padding: 0.75rem; overflow-x: auto; }
+    table { border-collapse: collapse; width: 100%; margin: 0.5rem 0 1rem; }
+    caption { text-align: left; font-weight: bold; }
+    th, td { border: 1px solid #d0d7de; padding: 0.25rem 0.5rem; text-align: left; vertical-align: top; }
+    article { border-top: 1px solid #d0d7de; padding: 0.5rem 0 1rem; }
+    .skip-link { position: absolute; left: -9999px; }
+    .skip-link:focus { left: 1rem; top: 1rem; background: #fff; padding: 0.5rem; }
+    .description { white-space: pre-line; }
+    .method { display: inline-block; min-width: 4.5rem; font-family: ui-monospace, monospace; }
+    .deprecated { color: #9a6700; }
+    .required { color: #cf222e; }
+  </style>
+</head>
+<body>
+  <a class="skip-link" href="#content">Skip to content</a>
+  {{- template "spec-selector" .Specs }}
+  <header>
+    <h1>{{ .Document.Title }}{{ with .Document.Version }} <small>{{ . }}</small>{{ end }}</h1>
+    {{- with .Document.Description }}
+    <p class="description">{{ . }}</p>
+    {{- end }}
+    {{- if .Document.Servers }}
+    <p>Servers:</p>
+    <ul>
+      {{- range .Document.Servers }}
+      <li><code>{{ . }}</code></li>
+      {{- end }}
+    </ul>
+    {{- end }}
+    <p><a href="{{ .SpecUrl }}">Download the OpenAPI specification</a></p>
+  </header>
+  <nav aria-label="Operations">
+    <h2>Contents</h2>
+    <ul>
+      {{- range .Document.Groups }}
+      <li><a href="#{{ .ID }}">{{ .Name }}</a>
+        <ul>
+          {{- range .Operations }}
+          <li><a href="#{{ .ID }}">{{ .Method }} {{ .Path }}</a>{{ with .Summary }} – {{ . }}{{ end }}</li>
+          {{- end }}
+        </ul>
+      </li>
+      {{- end }}
+    </ul>
+  </nav>
+  <main id="content">
+    {{- range .Document.Groups }}
+    <section aria-labelledby="{{ .ID }}">
+      <h2 id="{{ .ID }}">{{ .Name }}</h2>
+      {{- with .Description }}
+      <p class="description">{{ . }}</p>
+      {{- end }}
+      {{- range .Operations }}
+      <article aria-labelledby="{{ .ID }}">
+        <h3 id="{{ .ID }}"><span class="method">{{ .Method }}</span> <code>{{ .Path }}</code>{{ if .Deprecated }} <span class="deprecated">(deprecated)</span>{{ end }}</h3>
+        {{- with .Summary }}
+        <p><strong>{{ . }}</strong></p>
+        {{- end }}
+        {{- with .Description }}
+        <p class="description">{{ . }}</p>
+        {{- end }}
+        {{- if .Parameters }}
+        <table>
+          <caption>Parameters</caption>
+          <thead>
+            <tr><th scope="col">Name</th><th scope="col">In</th><th scope="col">Type</th><th scope="col">Required</th><th scope="col">Description</th></tr>
+          </thead>
+          <tbody>
+            {{- range .Parameters }}
+            <tr>
+              <th scope="row"><code>{{ .Name }}</code>{{ if .Deprecated }} <span class="deprecated">(deprecated)</span>{{ end }}</th>
+              <td>{{ .In }}</td>
+              <td>{{ with .Schema }}{{ template "schema-type" . }}{{ end }}</td>
+              <td>{{ if .Required }}Yes{{ else }}No{{ end }}</td>
+              <td class="description">{{ .Description }}</td>
+            </tr>
+            {{- end }}
+          </tbody>
+        </table>
+        {{- end }}
+        {{- with .RequestBody }}
+        <h4>Request body{{ if .Required }} <span class="required">(required)</span>{{ end }}</h4>
+        {{- with .Description }}
+        <p class="description">{{ . }}</p>
+        {{- end }}
+        {{- range .Contents }}
+        {{ template "content" . }}
+        {{- end }}
+        {{- end }}
+        {{- if .Responses }}
+        <h4>Responses</h4>
+        <dl>
+          {{- range .Responses }}
+          <dt><strong>{{ .Status }}</strong></dt>
+          <dd>
+            <p class="description">{{ .Description }}</p>
+            {{- range .Contents }}
+            {{ template "content" . }}
+            {{- end }}
+          </dd>
+          {{- end }}
+        </dl>
+        {{- end }}
+      </article>
+      {{- end }}
+    </section>
+    {{- end }}
+  </main>
+  {{- template "live-reload" .LiveReloadUrl }}
+</body>
+</html>
+{{- define "content" }}
+<div>
+  <p>Content type: <code>{{ .MediaType }}</code></p>
+  {{- with .Schema }}
+  <p>Schema: {{ template "schema-type" . }}</p>
+  {{- with .Description }}
+  <p class="description">{{ . }}</p>
+  {{- end }}
+  {{- if .Properties }}
+  {{ template "schema-properties" .Properties }}
+  {{- end }}
+  {{- end }}
+  {{- with .Example }}
+  <figure>
+    <figcaption>Example</figcaption>
+    <pre><code>{{ . }}</code></pre>
+  </figure>
+  {{- end }}
+</div>
+{{- end }}
+{{- define "schema-type" }}<code>{{ .Type }}</code>{{ with .Title }} {{ . }}{{ end }}{{ end }}
+{{- define "schema-properties" }}
+<ul>
+  {{- range . }}
+  <li>
+    <code>{{ .Name }}</code> {{ template "schema-type" . }}{{ if .Required }} <span class="required">(required)</span>{{ end }}
+    {{- with .Description }}
+    <div class="description">{{ . }}</div>
+    {{- end }}
+    {{- with .Enum }}
+    <div>Allowed values: {{ range $i, $v := . }}{{ if $i }}, {{ end }}<code>{{ $v }}</code>{{ end }}</div>
+    {{- end }}
+    {{- if .Properties }}
+    {{ template "schema-properties" .Properties }}
+    {{- end }}
+  </li>
+  {{- end }}
+</ul>
+{{- end }}
+`
+
+// errStaticHTMLSpecNotAvailable is returned when the static HTML renderer is used without a specification it can parse.
+var errStaticHTMLSpecNotAvailable = errors.New("the static HTML documentation requires Spec in JSON or YAML")
+
+// staticHTMLRenderer renders the OpenAPI documentation as static HTML.
+type staticHTMLRenderer struct {
+	config StaticHTMLConfig
+	tmpl   *htmltemplate.Template
+}
+
+// NewStaticHTMLRenderer returns a Renderer of the OpenAPI documentation as static HTML.
+// It renders the specification given to Documents in DocumentsConfig.Spec, so SpecUrl is not supported.
+// The specification and the HTTP settings in config, such as Spec and CacheControl, are not used by the renderer.
+// It returns an error if the configuration is invalid.
+func NewStaticHTMLRenderer(config StaticHTMLConfig) (Renderer, error) {
+	if config.Template == "" {
+		config.Template = DefaultStaticHTMLConfig.Template
+	}
+	if config.Title == "" {
+		config.Title = DefaultStaticHTMLConfig.Title
+	}
+
+	tmpl, err := parsePageTemplate(config.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	return &staticHTMLRenderer{
+		config: config,
+		tmpl:   tmpl,
+	}, nil
+}
+
+func (r *staticHTMLRenderer) Render(w io.Writer, page Page) error {
+	if page.Spec == nil {
+		return errStaticHTMLSpecNotAvailable
+	}
+	doc, err := newStaticHTMLSpec(page.Spec)
+	if err != nil {
+		return err
+	}
+	return r.tmpl.Execute(w, staticHTMLTemplateParams{
+		StaticHTMLConfig: r.config,
+		BasePath:         page.BasePath,
+		Specs:            page.Specs,
+		LiveReloadUrl:    page.LiveReloadUrl,
+		SpecUrl:          page.SpecUrl,
+		Document:         doc,
+	})
+}
+
+// NewStaticHTMLHandler returns an echo.HandlerFunc to serve the OpenAPI documentation as static HTML.
+// It returns an error if the configuration is invalid.
+func NewStaticHTMLHandler(config StaticHTMLConfig) (echo.HandlerFunc, error) {
+	if err := checkStaticHTMLSpecs(config); err != nil {
+		return nil, err
+	}
+
+	renderer, err := NewStaticHTMLRenderer(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewDocumentsHandler(DocumentsConfig{Spec: config.Spec, SiteConfig: config.SiteConfig}, renderer)
+}
+
+// checkStaticHTMLSpecs returns an error if a specification of config is not given by Spec, SpecSource or SharedSpec,
+// because it is rendered on the server.
+func checkStaticHTMLSpecs(config StaticHTMLConfig) error {
+	if len(config.Specs) == 0 && config.Spec == "" && config.SpecSource == nil && config.SharedSpec == nil {
+		return ErrSpecNotSet
+	}
+	for _, spec := range config.Specs {
+		if spec.Spec == "" && spec.SpecSource == nil && spec.SharedSpec == nil {
+			return fmt.Errorf("spec %q: %w", spec.Name, errStaticHTMLSpecNotAvailable)
+		}
+	}
+	return nil
+}
+
+// StaticHTMLDocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation as static HTML.
+// It panics if the configuration is invalid. Use NewStaticHTMLHandler to handle the error.
+func StaticHTMLDocumentsHandler(config StaticHTMLConfig) echo.HandlerFunc {
+	h, err := NewStaticHTMLHandler(config)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// StaticHTMLDocuments registers a handler to serve the OpenAPI documentation as static HTML.
+// It panics if the configuration is invalid. Use RegisterStaticHTMLDocuments to handle the error.
+func StaticHTMLDocuments(e *echo.Echo, pathPrefix string, config StaticHTMLConfig) {
+	if err := RegisterStaticHTMLDocuments(e, pathPrefix, config); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterStaticHTMLDocuments registers a handler to serve the OpenAPI documentation as static HTML on r,
+// which is either *echo.Echo or *echo.Group. The middleware is applied to the handler in addition to the middleware of r.
+// It returns an error if the configuration is invalid.
+func RegisterStaticHTMLDocuments(r Router, pathPrefix string, config StaticHTMLConfig, middleware ...echo.MiddlewareFunc) error {
+	if err := checkStaticHTMLSpecs(config); err != nil {
+		return err
+	}
+	renderer, err := NewStaticHTMLRenderer(config)
+	if err != nil {
+		return err
+	}
+	return registerDocuments(r, pathPrefix, DocumentsConfig{Spec: config.Spec, SiteConfig: config.SiteConfig}, renderer, openAPISpecKind, "Static HTML", config.Title, middleware)
+}
@@ -0,0 +1,599 @@
+package openapidocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// staticHTMLSpec is an OpenAPI specification arranged for the static HTML template.
+type staticHTMLSpec struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []string
+	// Groups is the operations grouped by tag.
+	// An operation with several tags appears in each of the groups, and operations without tags are in the `default` group.
+	Groups []*staticHTMLGroup
+}
+
+type staticHTMLGroup struct {
+	// ID is the id attribute of the heading of the group, unique in the page.
+	ID          string
+	Name        string
+	Description string
+	Operations  []*staticHTMLOperation
+}
+
+type staticHTMLOperation struct {
+	// ID is the id attribute of the heading of the operation, unique in the page.
+	ID          string
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Deprecated  bool
+	Parameters  []*staticHTMLParameter
+	RequestBody *staticHTMLRequestBody
+	Responses   []*staticHTMLResponse
+}
+
+type staticHTMLParameter struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	Deprecated  bool
+	Schema      *staticHTMLSchema
+}
+
+type staticHTMLRequestBody struct {
+	Description string
+	Required    bool
+	Contents    []*staticHTMLContent
+}
+
+type staticHTMLResponse struct {
+	Status      string
+	Description string
+	Contents    []*staticHTMLContent
+}
+
+type staticHTMLContent struct {
+	MediaType string
+	Schema    *staticHTMLSchema
+	// Example is the example of the content, formatted as indented JSON if possible.
+	Example string
+}
+
+// staticHTMLSchema is a schema or a property of a schema.
+type staticHTMLSchema struct {
+	// Name is the name of the property. It is empty for the root schema.
+	Name string
+	// Title is the name of the referenced schema or the title of the schema.
+	Title       string
+	Type        string
+	Required    bool
+	Description string
+	Enum        []string
+	Properties  []*staticHTMLSchema
+}
+
+const (
+	// staticHTMLDefaultGroup is the group of the operations without tags.
+	staticHTMLDefaultGroup = "default"
+	// staticHTMLMaxSchemaDepth limits the depth of the rendered schemas, which may be deeply nested.
+	staticHTMLMaxSchemaDepth = 8
+	// staticHTMLMaxRefHops limits the references followed to resolve a $ref, which may be a loop.
+	staticHTMLMaxRefHops = 32
+)
+
+// staticHTMLSpecBuilder builds a staticHTMLSpec from the node tree of a specification.
+type staticHTMLSpecBuilder struct {
+	root *yaml.Node
+	// ids counts the id attributes generated so far, to make them unique.
+	ids map[string]int
+}
+
+// newStaticHTMLSpec parses the specification in data and arranges it for the static HTML template.
+// It supports OpenAPI 3.x and Swagger 2.0 documents.
+func newStaticHTMLSpec(data []byte) (*staticHTMLSpec, error) {
+	root, _, err := parseSpecNode(data)
+	if err != nil {
+		return nil, err
+	}
+	b := &staticHTMLSpecBuilder{
+		root: root,
+		ids:  map[string]int{},
+	}
+	return b.build(), nil
+}
+
+func (b *staticHTMLSpecBuilder) build() *staticHTMLSpec {
+	spec := &staticHTMLSpec{}
+	if info := b.mapping(b.root, "info"); info != nil {
+		spec.Title = b.str(info, "title")
+		spec.Version = b.str(info, "version")
+		spec.Description = b.str(info, "description")
+	}
+	spec.Servers = b.servers()
+
+	groups := map[string]*staticHTMLGroup{}
+	group := func(name string) *staticHTMLGroup {
+		g, ok := groups[name]
+		if !ok {
+			g = &staticHTMLGroup{ID: b.id("tag-" + name), Name: name}
+			groups[name] = g
+			spec.Groups = append(spec.Groups, g)
+		}
+		return g
+	}
+	// The declared tags come first, in the declared order.
+	if tags := mappingValue(b.root, "tags"); tags != nil && tags.Kind == yaml.SequenceNode {
+		for _, t := range tags.Content {
+			t = resolveAlias(t)
+			if name := b.str(t, "name"); name != "" {
+				group(name).Description = b.str(t, "description")
+			}
+		}
+	}
+
+	paths := b.mapping(b.root, "paths")
+	if paths == nil {
+		return spec
+	}
+	keys, values := yamlMappingEntries(paths)
+	for i, p := range keys {
+		item, _ := b.resolve(values[i])
+		if item == nil || item.Kind != yaml.MappingNode {
+			continue
+		}
+		for _, method := range pathMethods {
+			op := mappingValue(item, method)
+			if op == nil || op.Kind != yaml.MappingNode {
+				continue
+			}
+			operation := b.operation(method, p, item, op)
+			tags := b.strs(op, "tags")
+			if len(tags) == 0 {
+				tags = []string{staticHTMLDefaultGroup}
+			}
+			for _, tag := range tags {
+				o := *operation
+				o.ID = b.id("operation-" + method + "-" + p)
+				g := group(tag)
+				g.Operations = append(g.Operations, &o)
+			}
+		}
+	}
+
+	// Drop the declared tags that have no operations.
+	used := spec.Groups[:0]
+	for _, g := range spec.Groups {
+		if len(g.Operations) > 0 {
+			used = append(used, g)
+		}
+	}
+	spec.Groups = used
+	return spec
+}
+
+// servers returns the URLs of the servers. Swagger 2.0 documents have a single server built from host and basePath.
+func (b *staticHTMLSpecBuilder) servers() []string {
+	var servers []string
+	if s := mappingValue(b.root, "servers"); s != nil && s.Kind == yaml.SequenceNode {
+		for _, server := range s.Content {
+			if url := b.str(resolveAlias(server), "url"); url != "" {
+				servers = append(servers, url)
+			}
+		}
+		return servers
+	}
+	if host := b.str(b.root, "host"); host != "" {
+		scheme := "https"
+		if schemes := b.strs(b.root, "schemes"); len(schemes) > 0 {
+			scheme = schemes[0]
+		}
+		servers = append(servers, scheme+"://"+host+b.str(b.root, "basePath"))
+	}
+	return servers
+}
+
+func (b *staticHTMLSpecBuilder) operation(method, p string, item, op *yaml.Node) *staticHTMLOperation {
+	operation := &staticHTMLOperation{
+		Method:      strings.ToUpper(method),
+		Path:        p,
+		Summary:     b.str(op, "summary"),
+		Description: b.str(op, "description"),
+		Deprecated:  b.bool(op, "deprecated"),
+	}
+
+	// The parameters of the operation override the parameters of the path with the same name and location.
+	var params []*yaml.Node
+	index := map[string]int{}
+	for _, list := range []*yaml.Node{mappingValue(item, "parameters"), mappingValue(op, "parameters")} {
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, n := range list.Content {
+			param, _ := b.resolve(n)
+			if param == nil || param.Kind != yaml.MappingNode {
+				continue
+			}
+			key := b.str(param, "in") + "/" + b.str(param, "name")
+			if i, ok := index[key]; ok {
+				params[i] = param
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, param)
+		}
+	}
+
+	for _, param := range params {
+		if b.str(param, "in") == "body" {
+			// Swagger 2.0 describes the request body as a parameter.
+			operation.RequestBody = &staticHTMLRequestBody{
+				Description: b.str(param, "description"),
+				Required:    b.bool(param, "required"),
+				Contents: []*staticHTMLContent{{
+					MediaType: b.mediaType(op, "consumes"),
+					Schema:    b.schema(mappingValue(param, "schema"), "", false, 0, nil),
+					Example:   b.example(mappingValue(mappingValue(param, "schema"), "example")),
+				}},
+			}
+			continue
+		}
+		schemaNode := mappingValue(param, "schema")
+		if schemaNode == nil {
+			// Swagger 2.0 parameters have the type in themselves.
+			schemaNode = param
+		}
+		operation.Parameters = append(operation.Parameters, &staticHTMLParameter{
+			Name:        b.str(param, "name"),
+			In:          b.str(param, "in"),
+			Description: b.str(param, "description"),
+			Required:    b.bool(param, "required"),
+			Deprecated:  b.bool(param, "deprecated"),
+			Schema:      b.schema(schemaNode, "", false, 0, nil),
+		})
+	}
+
+	if body, _ := b.resolve(mappingValue(op, "requestBody")); body != nil && body.Kind == yaml.MappingNode {
+		operation.RequestBody = &staticHTMLRequestBody{
+			Description: b.str(body, "description"),
+			Required:    b.bool(body, "required"),
+			Contents:    b.contents(mappingValue(body, "content")),
+		}
+	}
+
+	if responses := b.mapping(op, "responses"); responses != nil {
+		keys, values := yamlMappingEntries(responses)
+		for i, status := range keys {
+			res, _ := b.resolve(values[i])
+			if res == nil || res.Kind != yaml.MappingNode {
+				continue
+			}
+			response := &staticHTMLResponse{
+				Status:      status,
+				Description: b.str(res, "description"),
+				Contents:    b.contents(mappingValue(res, "content")),
+			}
+			if schema := mappingValue(res, "schema"); schema != nil {
+				// Swagger 2.0 responses have the schema in themselves.
+				response.Contents = append(response.Contents, &staticHTMLContent{
+					MediaType: b.mediaType(op, "produces"),
+					Schema:    b.schema(schema, "", false, 0, nil),
+					Example:   b.example(mappingValue(mappingValue(res, "examples"), b.mediaType(op, "produces"))),
+				})
+			}
+			operation.Responses = append(operation.Responses, response)
+		}
+	}
+	return operation
+}
+
+// mediaType returns the first media type of the Swagger 2.0 `consumes` or `produces` field of the operation,
+// falling back to the field of the document and then to application/json.
+func (b *staticHTMLSpecBuilder) mediaType(op *yaml.Node, field string) string {
+	for _, n := range []*yaml.Node{op, b.root} {
+		if types := b.strs(n, field); len(types) > 0 {
+			return types[0]
+		}
+	}
+	return "application/json"
+}
+
+// contents returns the media types of an OpenAPI 3.x `content` field.
+func (b *staticHTMLSpecBuilder) contents(content *yaml.Node) []*staticHTMLContent {
+	if content == nil || content.Kind != yaml.MappingNode {
+		return nil
+	}
+	var contents []*staticHTMLContent
+	keys, values := yamlMappingEntries(content)
+	for i, mediaType := range keys {
+		media, _ := b.resolve(values[i])
+		if media == nil || media.Kind != yaml.MappingNode {
+			continue
+		}
+		c := &staticHTMLContent{
+			MediaType: mediaType,
+			Schema:    b.schema(mappingValue(media, "schema"), "", false, 0, nil),
+		}
+		if example := mappingValue(media, "example"); example != nil {
+			c.Example = b.example(example)
+		} else if examples := b.mapping(media, "examples"); examples != nil && len(examples.Content) >= 2 {
+			// Use the first of the named examples.
+			if first, _ := b.resolve(examples.Content[1]); first != nil {
+				c.Example = b.example(mappingValue(first, "value"))
+			}
+		} else if schema, _ := b.resolve(mappingValue(media, "schema")); schema != nil {
+			c.Example = b.example(mappingValue(schema, "example"))
+		}
+		contents = append(contents, c)
+	}
+	return contents
+}
+
+// schema arranges a schema object. visiting is the schemas being arranged in the ancestors, to stop at circular references.
+func (b *staticHTMLSpecBuilder) schema(node *yaml.Node, name string, required bool, depth int, visiting map[*yaml.Node]bool) *staticHTMLSchema {
+	if node == nil {
+		return nil
+	}
+	resolved, ref := b.resolve(node)
+	s := &staticHTMLSchema{
+		Name:     name,
+		Title:    ref,
+		Required: required,
+	}
+	if resolved == nil {
+		s.Type = "unknown"
+		return s
+	}
+	if resolved.Kind != yaml.MappingNode {
+		// A boolean schema of OpenAPI 3.1.
+		s.Type = "any"
+		return s
+	}
+	if s.Title == "" {
+		s.Title = b.str(resolved, "title")
+	}
+	s.Description = b.str(resolved, "description")
+	s.Type = b.schemaType(resolved)
+	if enum := mappingValue(resolved, "enum"); enum != nil && enum.Kind == yaml.SequenceNode {
+		for _, v := range enum.Content {
+			s.Enum = append(s.Enum, resolveAlias(v).Value)
+		}
+	}
+
+	if visiting[resolved] {
+		s.Type += " (circular)"
+		return s
+	}
+	if depth >= staticHTMLMaxSchemaDepth {
+		return s
+	}
+	if visiting == nil {
+		visiting = map[*yaml.Node]bool{}
+	}
+	visiting[resolved] = true
+	defer delete(visiting, resolved)
+
+	for _, combinator := range []string{"allOf", "oneOf", "anyOf"} {
+		list := mappingValue(resolved, combinator)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for i, sub := range list.Content {
+			child := b.schema(sub, "", false, depth, visiting)
+			if combinator == "allOf" {
+				// The properties of all the schemas are the properties of the schema.
+				s.Properties = append(s.Properties, child.Properties...)
+				continue
+			}
+			child.Name = fmt.Sprintf("option %d", i+1)
+			s.Properties = append(s.Properties, child)
+		}
+	}
+
+	if items := mappingValue(resolved, "items"); items != nil {
+		item := b.schema(items, "", false, depth+1, visiting)
+		s.Type = "array of " + staticHTMLSchemaLabel(item)
+		s.Properties = append(s.Properties, item.Properties...)
+	}
+
+	requiredNames := map[string]bool{}
+	for _, n := range b.strs(resolved, "required") {
+		requiredNames[n] = true
+	}
+	if props := b.mapping(resolved, "properties"); props != nil {
+		keys, values := yamlMappingEntries(props)
+		for i, key := range keys {
+			s.Properties = append(s.Properties, b.schema(values[i], key, requiredNames[key], depth+1, visiting))
+		}
+	}
+	if additional := mappingValue(resolved, "additionalProperties"); additional != nil && additional.Kind == yaml.MappingNode {
+		s.Properties = append(s.Properties, b.schema(additional, "additional properties", false, depth+1, visiting))
+	}
+	return s
+}
+
+// schemaType returns the type of a schema, such as `string (date-time)` or `integer | null`.
+func (b *staticHTMLSpecBuilder) schemaType(schema *yaml.Node) string {
+	types := b.strs(schema, "type")
+	if len(types) == 0 {
+		switch {
+		case mappingValue(schema, "properties") != nil:
+			types = []string{"object"}
+		case mappingValue(schema, "items") != nil:
+			types = []string{"array"}
+		case mappingValue(schema, "oneOf") != nil:
+			types = []string{"one of"}
+		case mappingValue(schema, "anyOf") != nil:
+			types = []string{"any of"}
+		case mappingValue(schema, "allOf") != nil:
+			types = []string{"object"}
+		default:
+			types = []string{"any"}
+		}
+	}
+	t := strings.Join(types, " | ")
+	if format := b.str(schema, "format"); format != "" {
+		t += " (" + format + ")"
+	}
+	if b.bool(schema, "nullable") {
+		t += " | null"
+	}
+	return t
+}
+
+// staticHTMLSchemaLabel returns the short description of a schema, which is its title or its type.
+func staticHTMLSchemaLabel(s *staticHTMLSchema) string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return s.Type
+}
+
+// example formats an example value as indented JSON. Scalar values are returned as they are.
+func (b *staticHTMLSpecBuilder) example(node *yaml.Node) string {
+	node = resolveAlias(node)
+	if node == nil {
+		return ""
+	}
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	data, err := marshalNodeJSON(node)
+	if err != nil {
+		return ""
+	}
+	buf := new(bytes.Buffer)
+	if err := json.Indent(buf, data, "", "  "); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+// resolve follows the local reference ($ref) of node, and returns the referenced node and the name of the reference.
+// If node is not a reference, it returns node and an empty name. It returns nil if the reference can't be resolved.
+func (b *staticHTMLSpecBuilder) resolve(node *yaml.Node) (*yaml.Node, string) {
+	node = resolveAlias(node)
+	name := ""
+	for i := 0; node != nil && node.Kind == yaml.MappingNode; i++ {
+		ref := mappingValue(node, "$ref")
+		if ref == nil || ref.Kind != yaml.ScalarNode {
+			return node, name
+		}
+		if i >= staticHTMLMaxRefHops {
+			return nil, name
+		}
+		name = ref.Value[strings.LastIndex(ref.Value, "/")+1:]
+		node = b.lookup(ref.Value)
+	}
+	return node, name
+}
+
+// lookup returns the node at the local reference, such as `#/components/schemas/Pet`.
+func (b *staticHTMLSpecBuilder) lookup(ref string) *yaml.Node {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		// External references are not supported.
+		return nil
+	}
+	node := b.root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node.Kind {
+		case yaml.MappingNode:
+			node = mappingValue(node, token)
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil
+			}
+			node = resolveAlias(node.Content[i])
+		default:
+			return nil
+		}
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+var staticHTMLIDPattern = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// id returns a unique id attribute made from s.
+func (b *staticHTMLSpecBuilder) id(s string) string {
+	id := strings.Trim(staticHTMLIDPattern.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	b.ids[id]++
+	if n := b.ids[id]; n > 1 {
+		id += "-" + strconv.Itoa(n)
+	}
+	return id
+}
+
+// mapping returns the value of the key in node if it is a mapping, following a reference.
+func (b *staticHTMLSpecBuilder) mapping(node *yaml.Node, key string) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	value, _ := b.resolve(mappingValue(node, key))
+	if value == nil || value.Kind != yaml.MappingNode {
+		return nil
+	}
+	return value
+}
+
+// str returns the value of the key in node if it is a scalar.
+func (b *staticHTMLSpecBuilder) str(node *yaml.Node, key string) string {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return ""
+	}
+	value := mappingValue(node, key)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return value.Value
+}
+
+// strs returns the value of the key in node as a list of strings. A scalar value is a list of one string.
+func (b *staticHTMLSpecBuilder) strs(node *yaml.Node, key string) []string {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	value := mappingValue(node, key)
+	if value == nil {
+		return nil
+	}
+	if value.Kind == yaml.ScalarNode {
+		return []string{value.Value}
+	}
+	var strs []string
+	if value.Kind == yaml.SequenceNode {
+		for _, n := range value.Content {
+			if n = resolveAlias(n); n.Kind == yaml.ScalarNode {
+				strs = append(strs, n.Value)
+			}
+		}
+	}
+	return strs
+}
+
+// bool returns the value of the key in node if it is a boolean.
+func (b *staticHTMLSpecBuilder) bool(node *yaml.Node, key string) bool {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return false
+	}
+	value := mappingValue(node, key)
+	return value != nil && value.Kind == yaml.ScalarNode && value.ShortTag() == "!!bool" && strings.EqualFold(value.Value, "true")
+}
@@ -1,13 +1,11 @@
 package openapidocs
 
 import (
-	"bytes"
 	"encoding/json"
 	"github.com/labstack/echo/v4"
 	htmltemplate "html/template"
-	"net/http"
-	"path"
-	"strings"
+	"io"
+	"io/fs"
 )
 
 // SwaggerUIConfig is the configuration for SwaggerUIDocumentsHandler to generate the OpenAPI documentation with Swagger UI.
@@ -18,10 +16,28 @@ type SwaggerUIConfig struct {
 	Spec string
 	// SpecUrl is the URL of the OpenAPI specification. If Spec is not empty, SpecUrl is ignored.
 	SpecUrl string
+	// SiteConfig is the configuration of the documentation site that is common to all renderers,
+	// such as the source of the specification and HTTP caching.
+	SiteConfig
 	// Title is the title of the page.
 	Title string
 	// Template is a template string for rendering the page with html/template.
 	Template string
+	// UseEmbeddedAssets makes the page load the Swagger UI JavaScript and CSS files embedded in this package
+	// instead of loading them from a CDN. The files are served under the `_assets` path of the documentation site.
+	UseEmbeddedAssets bool
+	// AssetVersion is the version of Swagger UI to load. The default is the version of the embedded assets.
+	AssetVersion string
+	// ScriptIntegrity is the Subresource Integrity hash of the Swagger UI JavaScript file.
+	// If it is empty, the known hash of AssetVersion is used.
+	ScriptIntegrity string
+	// StylesheetIntegrity is the Subresource Integrity hash of the Swagger UI CSS file.
+	// If it is empty, the known hash of AssetVersion is used.
+	StylesheetIntegrity string
+	// StandalonePresetIntegrity is the Subresource Integrity hash of the Swagger UI standalone preset JavaScript file,
+	// which is loaded when the site serves multiple specifications.
+	// If it is empty, the known hash of AssetVersion is used.
+	StandalonePresetIntegrity string
 
 	// DeepLinking is the Swagger UI `deepLinking` configuration.
 	DeepLinking bool
@@ -34,112 +50,205 @@ type SwaggerUIConfig struct {
 type swaggerUITemplateParams struct {
 	SwaggerUIConfig
 	BasePath               string
+	Specs                  []PageSpec
+	Renderers              []PageRenderer
+	LiveReloadUrl          string
 	SwaggerUIConfiguration htmltemplate.JS
+	ScriptUrl              string
+	StylesheetUrl          string
+	StandalonePresetUrl    string
 }
 
 type swaggerUIConfiguration struct {
-	Url                string `json:"url"`
-	DomId              string `json:"dom_id"`
-	DeepLinking        bool   `json:"deepLinking,omitempty"`
-	DisplayOperationId bool   `json:"displayOperationId,omitempty"`
+	Url                string                      `json:"url"`
+	Urls               []swaggerUIConfigurationUrl `json:"urls,omitempty"`
+	UrlsPrimaryName    string                      `json:"urls.primaryName,omitempty"`
+	DomId              string                      `json:"dom_id"`
+	DeepLinking        bool                        `json:"deepLinking,omitempty"`
+	DisplayOperationId bool                        `json:"displayOperationId,omitempty"`
+}
+
+type swaggerUIConfigurationUrl struct {
+	Url  string `json:"url"`
+	Name string `json:"name"`
 }
 
 var DefaultSwaggerUIConfig = SwaggerUIConfig{
-	Spec:               "",
-	SpecUrl:            "",
-	Title:              "API documentation with Swagger UI",
-	Template:           defaultSwaggerUITemplate,
-	DeepLinking:        false,
-	DisplayOperationId: false,
+	Spec:                      "",
+	SpecUrl:                   "",
+	SiteConfig:                DefaultSiteConfig,
+	Title:                     "API documentation with Swagger UI",
+	Template:                  defaultSwaggerUITemplate,
+	UseEmbeddedAssets:         false,
+	AssetVersion:              swaggerUIAssets.version,
+	ScriptIntegrity:           "",
+	StylesheetIntegrity:       "",
+	StandalonePresetIntegrity: "",
+	DeepLinking:               false,
+	DisplayOperationId:        false,
 }
 
+const (
+	swaggerUIScriptFile           = "swagger-ui-bundle.js"
+	swaggerUIStylesheetFile       = "swagger-ui.css"
+	swaggerUIStandalonePresetFile = "swagger-ui-standalone-preset.js"
+)
+
 const defaultSwaggerUITemplate = `<html lang="en">
 <head>
   <meta charset="utf-8">
   <meta name="viewport" content="width=device-width, initial-scale=1">
   <title>{{ .Title }}</title>
-  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist/swagger-ui.css" />
+  <link rel="stylesheet" href="{{ .StylesheetUrl }}"{{ if .StylesheetIntegrity }} integrity="{{ .StylesheetIntegrity }}"{{ end }} crossorigin="anonymous" />
 </head>
 <body>
+  {{- template "renderer-switcher" .Renderers }}
   <div id="swagger-ui"></div>
-  <script src="https://unpkg.com/swagger-ui-dist/swagger-ui-bundle.js" crossorigin></script>
+  <script src="{{ .ScriptUrl }}"{{ if .ScriptIntegrity }} integrity="{{ .ScriptIntegrity }}"{{ end }} crossorigin="anonymous"></script>
+  {{- if .Specs }}
+  <script src="{{ .StandalonePresetUrl }}"{{ if .StandalonePresetIntegrity }} integrity="{{ .StandalonePresetIntegrity }}"{{ end }} crossorigin="anonymous"></script>
+  {{- end }}
   <script>
 	var configuration = {{ .SwaggerUIConfiguration }};
+	{{- if .Specs }}
+	// The definition selector of the urls option is a part of the standalone layout.
+	configuration.presets = [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset];
+	configuration.layout = "StandaloneLayout";
+	{{- end }}
     window.onload = () => {
 	  window.ui = SwaggerUIBundle(configuration);
     };
   </script>
+  {{- template "live-reload" .LiveReloadUrl }}
 </body>
 </html>
 `
 
-func SwaggerUIDocumentsHandler(config SwaggerUIConfig) echo.HandlerFunc {
+// swaggerUIRenderer renders the page of Swagger UI.
+type swaggerUIRenderer struct {
+	config SwaggerUIConfig
+	tmpl   *htmltemplate.Template
+	assets fs.FS
+}
+
+// NewSwaggerUIRenderer returns a Renderer of the OpenAPI documentation with Swagger UI.
+// The specification and the HTTP settings in config, such as Spec and CacheControl, are not used by the renderer.
+// Pass them to Documents in a DocumentsConfig.
+// It returns an error if the configuration is invalid.
+func NewSwaggerUIRenderer(config SwaggerUIConfig) (Renderer, error) {
 	if config.Template == "" {
 		config.Template = DefaultSwaggerUIConfig.Template
 	}
 	if config.Title == "" {
 		config.Title = DefaultSwaggerUIConfig.Title
 	}
+	if config.AssetVersion == "" {
+		config.AssetVersion = DefaultSwaggerUIConfig.AssetVersion
+	}
+	var err error
+	if config.ScriptIntegrity, err = swaggerUIAssets.integrityOf(config.AssetVersion, swaggerUIScriptFile, config.ScriptIntegrity, "ScriptIntegrity"); err != nil {
+		return nil, err
+	}
+	if config.StylesheetIntegrity, err = swaggerUIAssets.integrityOf(config.AssetVersion, swaggerUIStylesheetFile, config.StylesheetIntegrity, "StylesheetIntegrity"); err != nil {
+		return nil, err
+	}
+	if config.StandalonePresetIntegrity, err = swaggerUIAssets.integrityOf(config.AssetVersion, swaggerUIStandalonePresetFile, config.StandalonePresetIntegrity, "StandalonePresetIntegrity"); err != nil {
+		return nil, err
+	}
 
-	useSpecUrl := false
-	if config.Spec == "" {
-		if config.SpecUrl == "" {
-			panic("either Spec or SpecUrl must be set")
+	var assets fs.FS
+	if config.UseEmbeddedAssets {
+		if assets, err = swaggerUIAssets.embeddedFS(config.AssetVersion, swaggerUIScriptFile, swaggerUIStylesheetFile, swaggerUIStandalonePresetFile); err != nil {
+			return nil, err
 		}
-		useSpecUrl = true
 	}
 
-	pageTmpl := htmltemplate.Must(htmltemplate.New("T").Parse(config.Template))
-
-	return func(c echo.Context) error {
-		p := c.Request().URL.Path
+	tmpl, err := parsePageTemplate(config.Template)
+	if err != nil {
+		return nil, err
+	}
 
-		// determine the base path
-		relPath := c.Param("*")
-		basePath := strings.TrimSuffix(p, relPath)
+	return &swaggerUIRenderer{
+		config: config,
+		tmpl:   tmpl,
+		assets: assets,
+	}, nil
+}
 
-		var specUrl string
-		if !useSpecUrl {
-			specUrl = path.Join(basePath, "openapi-spec")
-			if strings.HasSuffix(p, specUrl) {
-				return c.Blob(http.StatusOK, "text/plain; charset=utf-8", []byte(config.Spec))
-			}
-		} else {
-			specUrl = config.SpecUrl
+func (r *swaggerUIRenderer) Render(w io.Writer, page Page) error {
+	swaggerUIConfiguration := swaggerUIConfiguration{
+		Url:                page.SpecUrl,
+		DomId:              "#swagger-ui",
+		DeepLinking:        r.config.DeepLinking,
+		DisplayOperationId: r.config.DisplayOperationId,
+	}
+	for _, spec := range page.Specs {
+		swaggerUIConfiguration.Urls = append(swaggerUIConfiguration.Urls, swaggerUIConfigurationUrl{
+			Url:  spec.Url,
+			Name: spec.Title,
+		})
+		if spec.Selected {
+			swaggerUIConfiguration.UrlsPrimaryName = spec.Title
 		}
+	}
 
-		if relPath != "" {
-			// The document site only works with the base path.
-			return c.Redirect(http.StatusFound, basePath)
-		}
+	jsonDate, err := json.Marshal(swaggerUIConfiguration)
+	if err != nil {
+		return err
+	}
 
-		swaggerUIConfiguration := swaggerUIConfiguration{
-			Url:                specUrl,
-			DomId:              "#swagger-ui",
-			DeepLinking:        config.DeepLinking,
-			DisplayOperationId: config.DisplayOperationId,
-		}
+	return r.tmpl.Execute(w, swaggerUITemplateParams{
+		SwaggerUIConfig:        r.config,
+		BasePath:               page.BasePath,
+		Specs:                  page.Specs,
+		Renderers:              page.Renderers,
+		LiveReloadUrl:          page.LiveReloadUrl,
+		SwaggerUIConfiguration: htmltemplate.JS(jsonDate),
+		ScriptUrl:              swaggerUIAssets.url(r.assets, page.BasePath, r.config.AssetVersion, swaggerUIScriptFile),
+		StylesheetUrl:          swaggerUIAssets.url(r.assets, page.BasePath, r.config.AssetVersion, swaggerUIStylesheetFile),
+		StandalonePresetUrl:    swaggerUIAssets.url(r.assets, page.BasePath, r.config.AssetVersion, swaggerUIStandalonePresetFile),
+	})
+}
 
-		jsonDate, err := json.Marshal(swaggerUIConfiguration)
-		if err != nil {
-			return err
-		}
+func (r *swaggerUIRenderer) Assets() fs.FS {
+	return r.assets
+}
 
-		params := swaggerUITemplateParams{
-			SwaggerUIConfig:        config,
-			BasePath:               basePath,
-			SwaggerUIConfiguration: htmltemplate.JS(jsonDate),
-		}
+// NewSwaggerUIHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with Swagger UI.
+// It returns an error if the configuration is invalid.
+func NewSwaggerUIHandler(config SwaggerUIConfig) (echo.HandlerFunc, error) {
+	renderer, err := NewSwaggerUIRenderer(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewDocumentsHandler(DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer)
+}
 
-		buf := new(bytes.Buffer)
-		if err := pageTmpl.Execute(buf, params); err != nil {
-			panic(err)
-		}
-		return c.HTML(http.StatusOK, buf.String())
+// SwaggerUIDocumentsHandler returns an echo.HandlerFunc to serve the OpenAPI documentation with Swagger UI.
+// It panics if the configuration is invalid. Use NewSwaggerUIHandler to handle the error.
+func SwaggerUIDocumentsHandler(config SwaggerUIConfig) echo.HandlerFunc {
+	h, err := NewSwaggerUIHandler(config)
+	if err != nil {
+		panic(err)
 	}
+	return h
 }
 
-// SwaggerUIDocuments registers a handler for serving Swagger UI documents.
+// SwaggerUIDocuments registers a handler to serve the OpenAPI documentation with Swagger UI.
+// It panics if the configuration is invalid. Use RegisterSwaggerUIDocuments to handle the error.
 func SwaggerUIDocuments(e *echo.Echo, pathPrefix string, config SwaggerUIConfig) {
-	e.GET(pathPrefix+"*", SwaggerUIDocumentsHandler(config))
+	if err := RegisterSwaggerUIDocuments(e, pathPrefix, config); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterSwaggerUIDocuments registers a handler to serve the OpenAPI documentation with Swagger UI on r,
+// which is either *echo.Echo or *echo.Group. The middleware is applied to the handler in addition to the middleware of r.
+// It returns an error if the configuration is invalid.
+func RegisterSwaggerUIDocuments(r Router, pathPrefix string, config SwaggerUIConfig, middleware ...echo.MiddlewareFunc) error {
+	renderer, err := NewSwaggerUIRenderer(config)
+	if err != nil {
+		return err
+	}
+	return registerDocuments(r, pathPrefix, DocumentsConfig{Spec: config.Spec, SpecUrl: config.SpecUrl, SiteConfig: config.SiteConfig}, renderer, openAPISpecKind, "Swagger UI", config.Title, middleware)
 }
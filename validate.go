@@ -0,0 +1,250 @@
+package openapidocs
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"strings"
+)
+
+// SpecError is an error found in an OpenAPI specification.
+type SpecError struct {
+	// Line is the line number of the error in the specification, starting at 1. It is 0 if unknown.
+	Line int
+	// Column is the column number of the error in the specification, starting at 1. It is 0 if unknown.
+	Column int
+	// Path is the location of the invalid value in the specification, such as `info.title`.
+	// It is empty for syntax errors.
+	Path string
+	// Message describes the error.
+	Message string
+}
+
+func (e *SpecError) Error() string {
+	var b strings.Builder
+	if e.Line > 0 {
+		fmt.Fprintf(&b, "line %d", e.Line)
+		if e.Column > 0 {
+			fmt.Fprintf(&b, ", column %d", e.Column)
+		}
+		b.WriteString(": ")
+	}
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// SpecValidationError is returned when an OpenAPI or AsyncAPI specification is invalid.
+// It contains all the errors found in the specification.
+type SpecValidationError struct {
+	Errors []*SpecError
+}
+
+func (e *SpecValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return "invalid specification: " + strings.Join(msgs, "; ")
+}
+
+// newSpecValidationError wraps err into a *SpecValidationError.
+func newSpecValidationError(err error) *SpecValidationError {
+	var validationErr *SpecValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr
+	}
+	var specErr *SpecError
+	if !errors.As(err, &specErr) {
+		specErr = &SpecError{Message: err.Error()}
+	}
+	return &SpecValidationError{Errors: []*SpecError{specErr}}
+}
+
+var (
+	openAPIVersionPattern  = regexp.MustCompile(`^3\.[01]\.\d+$`)
+	asyncAPIVersionPattern = regexp.MustCompile(`^[23]\.\d+\.\d+$`)
+	pathMethods            = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+)
+
+// specValidator collects the errors found in a specification.
+type specValidator struct {
+	errors []*SpecError
+}
+
+func (v *specValidator) errorf(node *yaml.Node, path string, format string, args ...any) {
+	v.errors = append(v.errors, &SpecError{
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validateSpecNode checks that the root node is an OpenAPI 3.0, 3.1 or Swagger 2.0 document
+// and has the required fields. It returns a *SpecValidationError if the document is invalid.
+func validateSpecNode(root *yaml.Node) error {
+	v := &specValidator{}
+
+	var version string
+	if n := mappingValue(root, "openapi"); n != nil {
+		if n.Kind != yaml.ScalarNode || n.ShortTag() != "!!str" {
+			v.errorf(n, "openapi", "must be a string")
+		} else if !openAPIVersionPattern.MatchString(n.Value) {
+			v.errorf(n, "openapi", "unsupported version %q, must be 3.0.x or 3.1.x", n.Value)
+		} else {
+			version = n.Value[:3]
+		}
+	} else if n := mappingValue(root, "swagger"); n != nil {
+		if n.Kind != yaml.ScalarNode || n.Value != "2.0" {
+			v.errorf(n, "swagger", "unsupported version %q, must be 2.0", n.Value)
+		} else {
+			version = "2.0"
+		}
+	} else {
+		v.errorf(root, "", "either `openapi` or `swagger` field is required")
+	}
+
+	if info := v.requireMapping(root, "info", ""); info != nil {
+		v.requireString(info, "title", "info")
+		v.requireString(info, "version", "info")
+	}
+
+	if version == "3.1" {
+		// OpenAPI 3.1 requires at least one of paths, components and webhooks.
+		if mappingValue(root, "paths") == nil && mappingValue(root, "components") == nil && mappingValue(root, "webhooks") == nil {
+			v.errorf(root, "", "at least one of `paths`, `components` and `webhooks` fields is required")
+		}
+		if paths := mappingValue(root, "paths"); paths != nil {
+			v.validatePaths(paths, version)
+		}
+	} else if paths := v.requireMapping(root, "paths", ""); paths != nil {
+		v.validatePaths(paths, version)
+	}
+
+	if len(v.errors) > 0 {
+		return &SpecValidationError{Errors: v.errors}
+	}
+	return nil
+}
+
+// validateAsyncAPISpecNode checks that the root node is an AsyncAPI 2.x or 3.x document
+// and has the required fields. It returns a *SpecValidationError if the document is invalid.
+func validateAsyncAPISpecNode(root *yaml.Node) error {
+	v := &specValidator{}
+
+	var version string
+	if n := mappingValue(root, "asyncapi"); n != nil {
+		if n.Kind != yaml.ScalarNode || n.ShortTag() != "!!str" {
+			v.errorf(n, "asyncapi", "must be a string")
+		} else if !asyncAPIVersionPattern.MatchString(n.Value) {
+			v.errorf(n, "asyncapi", "unsupported version %q, must be 2.x or 3.x", n.Value)
+		} else {
+			version = n.Value[:1]
+		}
+	} else {
+		v.errorf(root, "", "`asyncapi` field is required")
+	}
+
+	if info := v.requireMapping(root, "info", ""); info != nil {
+		v.requireString(info, "title", "info")
+		v.requireString(info, "version", "info")
+	}
+
+	// channels is optional in AsyncAPI 3.
+	if version == "3" {
+		if channels := mappingValue(root, "channels"); channels != nil && channels.Kind != yaml.MappingNode {
+			v.errorf(channels, "channels", "must be a mapping")
+		}
+	} else {
+		v.requireMapping(root, "channels", "")
+	}
+
+	if len(v.errors) > 0 {
+		return &SpecValidationError{Errors: v.errors}
+	}
+	return nil
+}
+
+func (v *specValidator) validatePaths(paths *yaml.Node, version string) {
+	if paths.Kind != yaml.MappingNode {
+		v.errorf(paths, "paths", "must be a mapping")
+		return
+	}
+	// The errors of the paths are reported at their keys. The keys merged from other mappings are reported at their values.
+	keyNodes := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		keyNodes[paths.Content[i].Value] = paths.Content[i]
+	}
+	keys, items := yamlMappingEntries(paths)
+	for i, key := range keys {
+		item := resolveAlias(items[i])
+		p := "paths." + key
+		if strings.HasPrefix(key, "x-") {
+			continue
+		}
+		if !strings.HasPrefix(key, "/") {
+			at := keyNodes[key]
+			if at == nil {
+				at = item
+			}
+			v.errorf(at, p, "path must begin with a slash")
+		}
+		if item.Kind != yaml.MappingNode {
+			v.errorf(item, p, "must be a mapping")
+			continue
+		}
+		for _, method := range pathMethods {
+			op := mappingValue(item, method)
+			if op == nil {
+				continue
+			}
+			if op.Kind != yaml.MappingNode {
+				v.errorf(op, p+"."+method, "must be a mapping")
+				continue
+			}
+			// responses is optional in OpenAPI 3.1.
+			if version != "3.1" {
+				v.requireMapping(op, "responses", p+"."+method)
+			}
+		}
+	}
+}
+
+// requireMapping reports an error if the key doesn't exist in the node or its value is not a mapping.
+// It returns the value if it is a mapping.
+func (v *specValidator) requireMapping(node *yaml.Node, key, path string) *yaml.Node {
+	value := mappingValue(node, key)
+	if value == nil {
+		v.errorf(node, path, "`%s` field is required", key)
+		return nil
+	}
+	if value.Kind != yaml.MappingNode {
+		v.errorf(value, joinSpecPath(path, key), "must be a mapping")
+		return nil
+	}
+	return value
+}
+
+// requireString reports an error if the key doesn't exist in the node or its value is not a string.
+func (v *specValidator) requireString(node *yaml.Node, key, path string) {
+	value := mappingValue(node, key)
+	if value == nil {
+		v.errorf(node, path, "`%s` field is required", key)
+		return
+	}
+	if value.Kind != yaml.ScalarNode || value.ShortTag() != "!!str" {
+		v.errorf(value, joinSpecPath(path, key), "must be a string")
+	}
+}
+
+func joinSpecPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,222 @@
+package openapidocs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateSpecNode(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want []SpecError
+	}{
+		{
+			name: "OpenAPI 3.0",
+			spec: "openapi: 3.0.3\ninfo:\n  title: Test\n  version: \"1\"\npaths:\n  /users:\n    get:\n      responses: {}\n",
+		},
+		{
+			name: "OpenAPI 3.1 without paths",
+			spec: "openapi: 3.1.0\ninfo:\n  title: Test\n  version: \"1\"\nwebhooks: {}\n",
+		},
+		{
+			name: "OpenAPI 3.1 operation without responses",
+			spec: "openapi: 3.1.0\ninfo:\n  title: Test\n  version: \"1\"\npaths:\n  /users:\n    get: {}\n",
+		},
+		{
+			name: "Swagger 2.0",
+			spec: "swagger: \"2.0\"\ninfo:\n  title: Test\n  version: \"1\"\npaths: {}\n",
+		},
+		{
+			name: "JSON",
+			spec: `{"openapi": "3.0.0", "info": {"title": "Test", "version": "1"}, "paths": {}}`,
+		},
+		{
+			name: "aliases and merge keys",
+			spec: "openapi: 3.0.0\ninfo: &info\n  title: Test\n  version: \"1\"\nx-info: *info\npaths:\n  /users: &users\n    get:\n      responses: {}\n  /people: *users\n",
+		},
+		{
+			name: "merge key in paths",
+			spec: "openapi: 3.0.0\ninfo:\n  title: Test\n  version: \"1\"\nx-paths: &paths\n  /users:\n    get:\n      responses: {}\npaths:\n  <<: *paths\n  /items:\n    get:\n      responses: {}\n",
+		},
+		{
+			name: "no version",
+			spec: "info:\n  title: Test\n  version: \"1\"\npaths: {}\n",
+			want: []SpecError{{Line: 1, Column: 1, Message: "either `openapi` or `swagger` field is required"}},
+		},
+		{
+			name: "unsupported version",
+			spec: "openapi: 2.0.0\ninfo:\n  title: Test\n  version: \"1\"\npaths: {}\n",
+			want: []SpecError{{Line: 1, Column: 10, Path: "openapi", Message: `unsupported version "2.0.0", must be 3.0.x or 3.1.x`}},
+		},
+		{
+			name: "version is not a string",
+			spec: `{"openapi": 3.1, "info": {"title": "Test", "version": "1"}, "paths": {}}`,
+			want: []SpecError{{Line: 1, Column: 13, Path: "openapi", Message: "must be a string"}},
+		},
+		{
+			name: "unsupported Swagger version",
+			spec: "swagger: \"1.2\"\ninfo:\n  title: Test\n  version: \"1\"\npaths: {}\n",
+			want: []SpecError{{Line: 1, Column: 10, Path: "swagger", Message: `unsupported version "1.2", must be 2.0`}},
+		},
+		{
+			name: "all the errors",
+			spec: "openapi: 3.0.0\ninfo:\n  version: 1\npaths:\n  users:\n    get: {}\n  /items: []\n",
+			want: []SpecError{
+				{Line: 3, Column: 3, Path: "info", Message: "`title` field is required"},
+				{Line: 3, Column: 12, Path: "info.version", Message: "must be a string"},
+				{Line: 5, Column: 3, Path: "paths.users", Message: "path must begin with a slash"},
+				{Line: 6, Column: 10, Path: "paths.users.get", Message: "`responses` field is required"},
+				{Line: 7, Column: 11, Path: "paths./items", Message: "must be a mapping"},
+			},
+		},
+		{
+			name: "info is not a mapping",
+			spec: "openapi: 3.0.0\ninfo: Test\npaths: {}\n",
+			want: []SpecError{{Line: 2, Column: 7, Path: "info", Message: "must be a mapping"}},
+		},
+		{
+			name: "OpenAPI 3.1 without paths, components and webhooks",
+			spec: "openapi: 3.1.0\ninfo:\n  title: Test\n  version: \"1\"\n",
+			want: []SpecError{{Line: 1, Column: 1, Message: "at least one of `paths`, `components` and `webhooks` fields is required"}},
+		},
+		{
+			name: "merged path without a slash",
+			spec: "openapi: 3.0.0\ninfo:\n  title: Test\n  version: \"1\"\nx-paths: &paths\n  users:\n    get:\n      responses: {}\npaths:\n  <<: *paths\n",
+			want: []SpecError{{Line: 7, Column: 5, Path: "paths.users", Message: "path must begin with a slash"}},
+		},
+		{
+			name: "operation is not a mapping",
+			spec: "openapi: 3.0.0\ninfo:\n  title: Test\n  version: \"1\"\npaths:\n  /users:\n    get: list\n",
+			want: []SpecError{{Line: 7, Column: 10, Path: "paths./users.get", Message: "must be a mapping"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, _, err := parseSpecNode([]byte(tt.spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertSpecErrors(t, validateSpecNode(root), tt.want)
+		})
+	}
+}
+
+func TestValidateAsyncAPISpecNode(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want []SpecError
+	}{
+		{
+			name: "AsyncAPI 2",
+			spec: "asyncapi: 2.6.0\ninfo:\n  title: Test\n  version: \"1\"\nchannels: {}\n",
+		},
+		{
+			name: "AsyncAPI 3 without channels",
+			spec: "asyncapi: 3.0.0\ninfo:\n  title: Test\n  version: \"1\"\n",
+		},
+		{
+			name: "AsyncAPI 2 without channels",
+			spec: "asyncapi: 2.6.0\ninfo:\n  title: Test\n  version: \"1\"\n",
+			want: []SpecError{{Line: 1, Column: 1, Message: "`channels` field is required"}},
+		},
+		{
+			name: "AsyncAPI 3 channels is not a mapping",
+			spec: "asyncapi: 3.0.0\ninfo:\n  title: Test\n  version: \"1\"\nchannels: []\n",
+			want: []SpecError{{Line: 5, Column: 11, Path: "channels", Message: "must be a mapping"}},
+		},
+		{
+			name: "unsupported version",
+			spec: "asyncapi: 1.2.0\ninfo:\n  title: Test\n  version: \"1\"\nchannels: {}\n",
+			want: []SpecError{{Line: 1, Column: 11, Path: "asyncapi", Message: `unsupported version "1.2.0", must be 2.x or 3.x`}},
+		},
+		{
+			name: "OpenAPI document",
+			spec: "openapi: 3.0.0\ninfo:\n  title: Test\n  version: \"1\"\npaths: {}\n",
+			want: []SpecError{
+				{Line: 1, Column: 1, Message: "`asyncapi` field is required"},
+				{Line: 1, Column: 1, Message: "`channels` field is required"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, _, err := parseSpecNode([]byte(tt.spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertSpecErrors(t, validateAsyncAPISpecNode(root), tt.want)
+		})
+	}
+}
+
+// assertSpecErrors checks that err is a *SpecValidationError with the errors, or nil if want is empty.
+func assertSpecErrors(t *testing.T, err error, want []SpecError) {
+	t.Helper()
+	if len(want) == 0 {
+		if err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+		return
+	}
+	var validationErr *SpecValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("err = %v, want a *SpecValidationError", err)
+	}
+	var got []SpecError
+	for _, e := range validationErr.Errors {
+		got = append(got, *e)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %+v, want %+v", got, want)
+	}
+}
+
+func TestSpecErrorError(t *testing.T) {
+	tests := []struct {
+		err  SpecError
+		want string
+	}{
+		{err: SpecError{Message: "the document is empty"}, want: "the document is empty"},
+		{err: SpecError{Line: 3, Message: "syntax error"}, want: "line 3: syntax error"},
+		{err: SpecError{Line: 3, Column: 5, Path: "info.title", Message: "must be a string"}, want: "line 3, column 5: info.title: must be a string"},
+		{err: SpecError{Path: "info", Message: "must be a mapping"}, want: "info: must be a mapping"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	validationErr := &SpecValidationError{Errors: []*SpecError{&tests[1].err, &tests[2].err}}
+	want := "invalid specification: line 3: syntax error; line 3, column 5: info.title: must be a string"
+	if got := validationErr.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestNewSpecValidationError(t *testing.T) {
+	specErr := &SpecError{Line: 1, Message: "syntax error"}
+	validationErr := &SpecValidationError{Errors: []*SpecError{specErr}}
+
+	tests := []struct {
+		name string
+		err  error
+		want []*SpecError
+	}{
+		{name: "validation error", err: validationErr, want: validationErr.Errors},
+		{name: "spec error", err: specErr, want: []*SpecError{specErr}},
+		{name: "other error", err: errors.New("failed"), want: []*SpecError{{Message: "failed"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newSpecValidationError(tt.err).Errors; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}